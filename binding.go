@@ -0,0 +1,169 @@
+package gomocket
+
+import (
+	"database/sql/driver"
+	"strings"
+)
+
+// sqlWord is a word of query used to find which columns args are bound to
+type sqlWord struct {
+	text        string // Upper cased keyword, unquoted identifier or punctuation
+	ident       string // Identifier as it is written in query, unquoted
+	quoted      bool   // Identifier is quoted, so it is never a keyword
+	placeholder *sqlToken
+}
+
+// sqlWords splits query into keywords, identifiers, punctuation and placeholders
+func sqlWords(query string) []sqlWord {
+	var words []sqlWord
+	for _, t := range lexSQL(query, Catcher.dialect()) {
+		switch t.kind {
+		case tokenPlaceholder:
+			token := t
+			words = append(words, sqlWord{text: "?", placeholder: &token})
+		case tokenQuotedIdent:
+			ident := t.text[1 : len(t.text)-1]
+			// "users"."id" are glued together, only the column name is kept
+			if len(words) > 0 && words[len(words)-1].text == "." {
+				words = words[:len(words)-1]
+				if len(words) > 0 && words[len(words)-1].ident != "" {
+					words = words[:len(words)-1]
+				}
+			}
+			words = append(words, sqlWord{text: strings.ToUpper(ident), ident: ident, quoted: true})
+		case tokenString:
+			words = append(words, sqlWord{text: "'"})
+		case tokenText:
+			words = append(words, textWords(t.text)...)
+		}
+	}
+	return words
+}
+
+// textWords splits plain text into words
+func textWords(text string) []sqlWord {
+	var words []sqlWord
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case isIdentChar(c):
+			end := scanIdent(text, i)
+			ident := text[i:end]
+			if len(words) > 0 && words[len(words)-1].text == "." {
+				// table.column, only the column name is kept
+				words = words[:len(words)-1]
+				if len(words) > 0 && words[len(words)-1].ident != "" {
+					words = words[:len(words)-1]
+				}
+			}
+			if isDigit(c) {
+				words = append(words, sqlWord{text: ident})
+			} else {
+				words = append(words, sqlWord{text: strings.ToUpper(ident), ident: ident})
+			}
+			i = end
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(text[i:], "<>") || strings.HasPrefix(text[i:], "!=") ||
+			strings.HasPrefix(text[i:], "<=") || strings.HasPrefix(text[i:], ">="):
+			words = append(words, sqlWord{text: text[i : i+2]})
+			i += 2
+		default:
+			words = append(words, sqlWord{text: string(c)})
+			i++
+		}
+	}
+	return words
+}
+
+// argBindings finds which columns args are bound to. For INSERT every tuple of VALUES gives a record
+// with columns from the column list. For other statements a single record is built from
+// column = ? comparisons and assignments. Values of :name placeholders are bound to their names
+// when nothing else is found.
+func argBindings(query string, args []driver.NamedValue) []map[string]interface{} {
+	words := sqlWords(query)
+	if records := insertBindings(words, args); records != nil {
+		return records
+	}
+	record := make(map[string]interface{})
+	for i, w := range words {
+		if w.placeholder == nil {
+			continue
+		}
+		arg, ok := placeholderArg(*w.placeholder, args)
+		if !ok {
+			continue
+		}
+		switch {
+		case i >= 2 && words[i-1].text == "=" && words[i-2].ident != "":
+			record[words[i-2].ident] = arg.Value
+		case i+2 < len(words) && words[i+1].text == "=" && words[i+2].ident != "":
+			record[words[i+2].ident] = arg.Value
+		case w.placeholder.style == placeholderColon:
+			if _, ok := record[w.placeholder.name]; !ok {
+				record[w.placeholder.name] = arg.Value
+			}
+		}
+	}
+	if len(record) == 0 {
+		return nil
+	}
+	return []map[string]interface{}{record}
+}
+
+// insertBindings builds records from INSERT INTO table (columns) VALUES (...), (...), nil if it is not such statement
+func insertBindings(words []sqlWord, args []driver.NamedValue) []map[string]interface{} {
+	if len(words) < 3 || words[0].text != "INSERT" {
+		return nil
+	}
+	i := 1
+	for i < len(words) && words[i].text != "(" {
+		if words[i].text == "VALUES" || words[i].text == "SELECT" {
+			return nil
+		}
+		i++
+	}
+	var columns []string
+	for i++; i < len(words) && words[i].text != ")"; i++ {
+		if words[i].ident != "" {
+			columns = append(columns, words[i].ident)
+		}
+	}
+	for i < len(words) && words[i].text != "VALUES" {
+		i++
+	}
+	var records []map[string]interface{}
+	for i < len(words) {
+		if words[i].text != "(" {
+			if words[i].text == "VALUES" || words[i].text == "," {
+				i++
+				continue
+			}
+			break
+		}
+		record := make(map[string]interface{})
+		column, depth := 0, 0
+		for i++; i < len(words); i++ {
+			w := words[i]
+			if w.text == "(" {
+				depth++
+			} else if w.text == ")" {
+				if depth == 0 {
+					break
+				}
+				depth--
+			} else if w.text == "," && depth == 0 {
+				column++
+			} else if w.placeholder != nil && depth == 0 && column < len(columns) {
+				if arg, ok := placeholderArg(*w.placeholder, args); ok {
+					record[columns[column]] = arg.Value
+				}
+			} else if w.text == "NULL" && depth == 0 && column < len(columns) {
+				record[columns[column]] = nil
+			}
+		}
+		i++
+		records = append(records, record)
+	}
+	return records
+}
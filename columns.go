@@ -0,0 +1,119 @@
+package gomocket
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+)
+
+// Nullability says if column could hold NULL values
+type Nullability int
+
+const (
+	// NullabilityUnknown means nullability is inferred from reply values, column without rows is reported nullable
+	NullabilityUnknown Nullability = iota
+	// Nullable column could hold NULL values
+	Nullable
+	// NotNull column never holds NULL values
+	NotNull
+)
+
+// ColumnType describes column of reply for rows.ColumnTypes(), everything not declared is inferred from values
+type ColumnType struct {
+	DatabaseTypeName string       // Type name like VARCHAR or BIGINT, inferred from values if empty
+	ScanType         reflect.Type // Go type to scan into, derived from DatabaseTypeName or values if nil
+	Nullable         Nullability  // If column could hold NULL values
+	Length           int64        // Length of variable length types, 0 means not declared
+	Precision        int64        // Precision of decimal types, 0 means not declared
+	Scale            int64        // Scale of decimal types
+}
+
+// WithColumnType declares type of reply column, all columns with the name share it
+// example: WithColumnType("price", ColumnType{DatabaseTypeName: "DECIMAL", Precision: 10, Scale: 2})
+func (fr *FakeResponse) WithColumnType(column string, ct ColumnType) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.ColumnTypes == nil {
+		fr.ColumnTypes = make(map[string]ColumnType)
+	}
+	fr.ColumnTypes[column] = ct
+	return fr
+}
+
+// resolveColumnTypes completes declared column types with types inferred from values of the rows
+func resolveColumnTypes(columns []string, declared map[string]ColumnType, rows []*row) []ColumnType {
+	types := make([]ColumnType, len(columns))
+	for i, col := range columns {
+		ct := declared[col]
+		var sample interface{}
+		hasNull := false
+		for _, r := range rows {
+			if r.cols[i] == nil {
+				hasNull = true
+			} else if sample == nil {
+				sample = r.cols[i]
+			}
+		}
+		if ct.Nullable == NullabilityUnknown && len(rows) > 0 {
+			ct.Nullable = NotNull
+			if hasNull {
+				ct.Nullable = Nullable
+			}
+		}
+		if ct.DatabaseTypeName == "" {
+			ct.DatabaseTypeName = databaseTypeOf(sample)
+		}
+		if ct.ScanType == nil {
+			ct.ScanType = colTypeToReflectType(ct.DatabaseTypeName)
+		}
+		if ct.ScanType == nil && sample != nil {
+			ct.ScanType = reflect.TypeOf(sample)
+		}
+		if ct.ScanType == nil {
+			ct.ScanType = reflect.TypeOf((*interface{})(nil)).Elem()
+		}
+		if ct.Nullable != NotNull {
+			ct.ScanType = nullScanType(ct.ScanType)
+		}
+		types[i] = ct
+	}
+	return types
+}
+
+// databaseTypeOf infers database type name of the value, empty if nothing could be inferred
+func databaseTypeOf(value interface{}) string {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "BIGINT"
+	case float32, float64:
+		return "DOUBLE"
+	case bool:
+		return "BOOLEAN"
+	case string:
+		return "VARCHAR"
+	case []byte:
+		return "BLOB"
+	case time.Time:
+		return "TIMESTAMP"
+	}
+	return ""
+}
+
+// nullScanType returns sql.Null* type for types which have it
+func nullScanType(t reflect.Type) reflect.Type {
+	switch t {
+	case reflect.TypeOf(int64(0)):
+		return reflect.TypeOf(sql.NullInt64{})
+	case reflect.TypeOf(int32(0)):
+		return reflect.TypeOf(sql.NullInt32{})
+	case reflect.TypeOf(float64(0)):
+		return reflect.TypeOf(sql.NullFloat64{})
+	case reflect.TypeOf(false):
+		return reflect.TypeOf(sql.NullBool{})
+	case reflect.TypeOf(""):
+		return reflect.TypeOf(sql.NullString{})
+	case reflect.TypeOf(time.Time{}):
+		return reflect.TypeOf(sql.NullTime{})
+	}
+	return t
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"database/sql/driver"
 	"errors"
-	"log"
-	"regexp"
 	"strings"
 	"sync"
 )
@@ -71,15 +69,15 @@ func (c *FakeConn) Prepare(query string) (driver.Stmt, error) {
 // it must not store the context within the statement itself.
 func (c *FakeConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
 	var firstStmt = &FakeStmt{q: query, connection: c}
-	// Checking how many placeholders do we have
-	if strings.Contains(query, "$1") {
-		r, err := regexp.Compile(`[$]\d+`)
-		if err != nil {
-			log.Fatalf(`Cant't compile regexp with err [%v]`, err)
+	// Checking how many placeholders do we have, ?, $1, :name and @p1 notations are supported
+	tokens := lexSQL(query, Catcher.dialect())
+	firstStmt.placeholders = countInputs(tokens)
+
+	if Catcher.splitStatements() {
+		if head := splitStatements(c, tokens); head != nil {
+			head.placeholders = firstStmt.placeholders
+			return head, nil
 		}
-		firstStmt.placeholders = len(strings.Split(r.ReplaceAllString(query, `$$$`), "$$")) - 1 // Postgres notation
-	} else {
-		firstStmt.placeholders = len(strings.Split(query, "?")) - 1 // Postgres notation
 	}
 
 	queryParts := strings.Split(query, " ") // By First statement define the query type
@@ -0,0 +1,192 @@
+package gomocket
+
+import (
+	"database/sql/driver"
+	"strings"
+	"time"
+)
+
+// Dataset creates mock replying to queries matching pattern with rows of table-like dataset.
+// Rows are filtered by WHERE clause made of column = ? and column IS [NOT] NULL conditions joined by AND,
+// every row is returned when the clause could not be understood. Only selected columns are returned
+// when SELECT list of the query is known and names columns of dataset, aliases rename them.
+// example: Catcher.Dataset(`FROM "users"`, users) replies to `SELECT * FROM "users" WHERE "id" = ?` with matching user
+func (mc *MockCatcher) Dataset(pattern string, rows []map[string]interface{}) *FakeResponse {
+	return mc.NewMock().WithQuery(pattern).WithDataset(rows)
+}
+
+// WithDataset sets rows of table-like dataset filtered by WHERE clause of every query
+func (fr *FakeResponse) WithDataset(rows []map[string]interface{}) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.Dataset = rows
+	fr.convertReplies()
+	return fr
+}
+
+// condition is a condition of WHERE clause understood by dataset
+type condition struct {
+	column string
+	value  interface{}
+	isNull bool // column IS NULL, value is ignored
+	negate bool // column IS NOT NULL
+}
+
+// whereConditions parses WHERE clause of statement, false if it has anything but
+// column = ? and column IS [NOT] NULL conditions joined by AND
+func whereConditions(statement string, args []driver.NamedValue) ([]condition, bool) {
+	words := sqlWords(statement)
+	start, depth := -1, 0
+	for i, w := range words {
+		if w.text == "(" {
+			depth++
+		} else if w.text == ")" {
+			depth--
+		} else if w.text == "WHERE" && depth == 0 {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return nil, true
+	}
+
+	var conditions []condition
+	var current []sqlWord
+	depth = 0
+	for i := start; i <= len(words); i++ {
+		if i < len(words) {
+			w := words[i]
+			if w.text == "(" {
+				depth++
+			} else if w.text == ")" {
+				depth--
+			}
+			end := w.text == "ORDER" || w.text == "GROUP" || w.text == "LIMIT" || w.text == "OFFSET" || w.text == "FOR" || w.text == ";"
+			if depth < 0 || (depth == 0 && end) {
+				i = len(words)
+			} else if depth > 0 || w.text != "AND" {
+				current = append(current, w)
+				continue
+			}
+		}
+		cond, ok := parseCondition(stripParens(current), args)
+		if !ok {
+			return nil, false
+		}
+		conditions = append(conditions, cond)
+		current = nil
+	}
+	return conditions, true
+}
+
+// stripParens removes parentheses wrapping the whole condition
+func stripParens(words []sqlWord) []sqlWord {
+	for len(words) >= 2 && words[0].text == "(" && words[len(words)-1].text == ")" {
+		words = words[1 : len(words)-1]
+	}
+	return words
+}
+
+// parseCondition parses column = ?, ? = column and column IS [NOT] NULL
+func parseCondition(words []sqlWord, args []driver.NamedValue) (condition, bool) {
+	switch {
+	case len(words) == 3 && words[1].text == "=" && words[0].ident != "" && words[2].placeholder != nil:
+		arg, ok := placeholderArg(*words[2].placeholder, args)
+		return condition{column: words[0].ident, value: arg.Value}, ok
+	case len(words) == 3 && words[1].text == "=" && words[2].ident != "" && words[0].placeholder != nil:
+		arg, ok := placeholderArg(*words[0].placeholder, args)
+		return condition{column: words[2].ident, value: arg.Value}, ok
+	case len(words) == 3 && words[0].ident != "" && words[1].text == "IS" && words[2].text == "NULL":
+		return condition{column: words[0].ident, isNull: true}, true
+	case len(words) == 4 && words[0].ident != "" && words[1].text == "IS" && words[2].text == "NOT" && words[3].text == "NULL":
+		return condition{column: words[0].ident, isNull: true, negate: true}, true
+	}
+	return condition{}, false
+}
+
+// filterDataset returns rows of dataset matching all conditions
+func filterDataset(rows []map[string]interface{}, conditions []condition) []map[string]interface{} {
+	filtered := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		if matchesConditions(row, conditions) {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+// projectDataset keeps only selected columns of dataset rows, aliased columns are renamed.
+// Rows are returned as they are if some item is an expression or a column missing in dataset.
+func projectDataset(rows []map[string]interface{}, selected []selectItem) []map[string]interface{} {
+	for _, item := range selected {
+		if item.source == "" || !hasColumn(rows, item.source) {
+			return rows
+		}
+	}
+	projected := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		projected[i] = make(map[string]interface{}, len(selected))
+		for _, item := range selected {
+			projected[i][item.name] = columnValue(row, item.source)
+		}
+	}
+	return projected
+}
+
+// hasColumn returns true if any row of dataset has the column, names are compared case-insensitively
+func hasColumn(rows []map[string]interface{}, column string) bool {
+	for _, row := range rows {
+		for col := range row {
+			if strings.EqualFold(col, column) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// columnValue returns value of column, names are compared case-insensitively
+func columnValue(row map[string]interface{}, column string) interface{} {
+	for col, v := range row {
+		if strings.EqualFold(col, column) {
+			return v
+		}
+	}
+	return nil
+}
+
+// matchesConditions returns true when row matches all conditions
+func matchesConditions(row map[string]interface{}, conditions []condition) bool {
+	for _, cond := range conditions {
+		value := columnValue(row, cond.column)
+		if cond.isNull {
+			if (value == nil) == cond.negate {
+				return false
+			}
+			continue
+		}
+		if !equalValues(value, cond.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValues compares driver values, []byte is equal to string with the same content
+func equalValues(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if ab, ok := a.([]byte); ok {
+		a = string(ab)
+	}
+	if bb, ok := b.([]byte); ok {
+		b = string(bb)
+	}
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return a == b
+}
@@ -0,0 +1,106 @@
+package gomocket
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect defines how args are rendered when they are put into query instead of placeholders
+type Dialect int
+
+const (
+	// DialectDefault renders values as ANSI SQL literals
+	DialectDefault Dialect = iota
+	// DialectMySQL escapes backslashes in strings and renders blobs as X'...'
+	DialectMySQL
+	// DialectPostgres renders blobs as '\x...' and times with time zone
+	DialectPostgres
+	// DialectSQLite renders booleans as 1 and 0
+	DialectSQLite
+	// DialectSQLServer renders booleans as 1 and 0 and blobs as 0x...
+	DialectSQLServer
+)
+
+// Literal renders Go value as SQL literal of the dialect
+func (d Dialect) Literal(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return d.boolLiteral(v)
+	case string:
+		return d.stringLiteral(v)
+	case []byte:
+		if v == nil {
+			return "NULL"
+		}
+		return d.bytesLiteral(v)
+	case time.Time:
+		return d.timeLiteral(v)
+	default:
+		return d.stringLiteral(fmt.Sprintf("%v", v))
+	}
+}
+
+func (d Dialect) boolLiteral(v bool) string {
+	switch d {
+	case DialectSQLite, DialectSQLServer:
+		if v {
+			return "1"
+		}
+		return "0"
+	}
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (d Dialect) stringLiteral(v string) string {
+	if d == DialectMySQL {
+		v = strings.ReplaceAll(v, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+func (d Dialect) bytesLiteral(v []byte) string {
+	switch d {
+	case DialectPostgres:
+		return `'\x` + hex.EncodeToString(v) + "'"
+	case DialectSQLServer:
+		return "0x" + strings.ToUpper(hex.EncodeToString(v))
+	}
+	return "X'" + strings.ToUpper(hex.EncodeToString(v)) + "'"
+}
+
+func (d Dialect) timeLiteral(v time.Time) string {
+	switch d {
+	case DialectPostgres:
+		return "'" + v.Format("2006-01-02 15:04:05.999999999-07:00") + "'"
+	case DialectSQLServer:
+		return "'" + v.Format("2006-01-02T15:04:05.9999999") + "'"
+	}
+	return "'" + v.Format("2006-01-02 15:04:05.999999999") + "'"
+}
+
+// SetDialect sets dialect used to render args in received queries
+func (mc *MockCatcher) SetDialect(d Dialect) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.Dialect = d
+}
+
+func (mc *MockCatcher) dialect() Dialect {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.Dialect
+}
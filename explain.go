@@ -0,0 +1,144 @@
+package gomocket
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// MockExplanation describes result of every check of one mock against a query
+type MockExplanation struct {
+	Mock          *FakeResponse // Checked mock
+	PatternMatch  bool          // Query matches the pattern
+	ArgsMatch     bool          // Args match the expected ones
+	ScenarioMatch bool          // Scenario of the mock is in required state
+	CallsAllowed  bool          // Once, Times, OnCall and AfterCalls budget allows to trigger the mock
+	CallsReason   string        // Why calls budget does not allow to trigger the mock
+	Matched       bool          // All checks passed
+}
+
+// Explanation describes why the query did or did not match registered mocks
+type Explanation struct {
+	Query  string            // Normalized query with args
+	Mocks  []MockExplanation // All mocks in order they are checked
+	Winner *FakeResponse     // Mock which handles the query, nil if nothing matched
+	Reason string            // Why the winner is picked among other matched mocks
+}
+
+// Explain checks query and args against all mocks without triggering any of them. Queries returning rows
+// are matched with args put in place of ? the way db.Query does, other statements as they are like db.Exec.
+// example: fmt.Println(Catcher.Explain("SELECT * FROM users WHERE id = ?", 1))
+func (mc *MockCatcher) Explain(query string, args ...interface{}) *Explanation {
+	namedArgs := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		namedArgs[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	statement := normalize(query)
+	matched := statement
+	if returnsRows(statement) {
+		matched = normalize(matchingStatement(statement, namedArgs))
+	}
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.sortMocks()
+	return mc.explain(matched, completeStatement(statement, namedArgs, mc.Dialect), namedArgs, false)
+}
+
+// returnsRows guesses by leading command if statement is sent by db.Query rather than db.Exec,
+// INSERT, UPDATE and DELETE with RETURNING clause return rows too
+func returnsRows(statement string) bool {
+	fields := strings.Fields(statement)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "WITH", "SHOW", "VALUES", "EXPLAIN", "DESCRIBE", "PRAGMA":
+		return true
+	case "INSERT", "UPDATE", "DELETE":
+		_, ok := returningColumns(sqlWords(statement))
+		return ok
+	}
+	return false
+}
+
+// explain builds explanation for normalized query, counted says if calls of this query are already counted by mocks
+func (mc *MockCatcher) explain(query, queryWithArgs string, args []driver.NamedValue, counted bool) *Explanation {
+	e := &Explanation{Query: queryWithArgs}
+	var matched []*FakeResponse
+	for _, resp := range mc.Mocks {
+		me := MockExplanation{
+			Mock:          resp,
+			PatternMatch:  resp.isQueryMatch(query),
+			ArgsMatch:     resp.isArgsMatch(args),
+			ScenarioMatch: mc.isScenarioMatch(resp),
+		}
+		resp.mu.RLock()
+		call := resp.MatchedTimes + 1
+		if counted && me.PatternMatch && me.ArgsMatch && me.ScenarioMatch {
+			call = resp.MatchedTimes
+		}
+		me.CallsReason = resp.callsBudgetViolation(call)
+		resp.mu.RUnlock()
+		me.CallsAllowed = me.CallsReason == ""
+		me.Matched = me.PatternMatch && me.ArgsMatch && me.ScenarioMatch && me.CallsAllowed
+		if me.Matched {
+			matched = append(matched, resp)
+		}
+		e.Mocks = append(e.Mocks, me)
+	}
+	if len(matched) == 0 {
+		return e
+	}
+	e.Winner = matched[0]
+	e.Reason = winReason(matched)
+	return e
+}
+
+// winReason explains why the first of matched mocks is picked
+func winReason(matched []*FakeResponse) string {
+	if len(matched) == 1 {
+		return "the only matched mock"
+	}
+	winner, next := matched[0], matched[1]
+	winner.mu.RLock()
+	defer winner.mu.RUnlock()
+	next.mu.RLock()
+	defer next.mu.RUnlock()
+	if winner.MatchPriority != next.MatchPriority {
+		return fmt.Sprintf("higher match priority %d over %d", winner.MatchPriority, next.MatchPriority)
+	}
+	if len(winner.Pattern) != len(next.Pattern) {
+		return fmt.Sprintf("longer pattern %d chars over %d", len(winner.Pattern), len(next.Pattern))
+	}
+	return "registered earlier than mock with the same priority and pattern length"
+}
+
+// String formats explanation to be printed in logs
+func (e *Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "mock_catcher: explain query: %s\n", e.Query)
+	for i, me := range e.Mocks {
+		fmt.Fprintf(&b, "  #%d %s: pattern %s, args %s, scenario %s, calls %s", i+1, describeMock(me.Mock),
+			checkResult(me.PatternMatch), checkResult(me.ArgsMatch), checkResult(me.ScenarioMatch), checkResult(me.CallsAllowed))
+		if me.CallsReason != "" {
+			fmt.Fprintf(&b, " (%s)", me.CallsReason)
+		}
+		if me.Mock == e.Winner {
+			b.WriteString(" <- winner")
+		}
+		b.WriteString("\n")
+	}
+	if e.Winner == nil {
+		b.WriteString("  no mock matched")
+	} else {
+		fmt.Fprintf(&b, "  winner: %s", e.Reason)
+	}
+	return b.String()
+}
+
+func checkResult(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "FAIL"
+}
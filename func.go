@@ -0,0 +1,38 @@
+package gomocket
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// ReplyFunc computes reply of query from query and its args. Query is the statement with placeholders
+// as it was prepared, the same as ExecFunc gets, args are never put in place.
+type ReplyFunc func(ctx context.Context, query string, args []driver.NamedValue) (*ReplySet, error)
+
+// ExecFunc computes result of statement which returns no rows from query with placeholders and its args
+type ExecFunc func(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error)
+
+// WithReplyFunc sets function computing reply on every trigger, e.g. to echo IDs from args.
+// Returned error fails the query, nil reply gives no rows.
+//
+//	WithReplyFunc(func(ctx context.Context, query string, args []driver.NamedValue) (*ReplySet, error) {
+//		return NewReplySet().WithColumns("id").AddRow(args[0].Value), nil
+//	})
+func (fr *FakeResponse) WithReplyFunc(f ReplyFunc) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.ReplyFunc = f
+	return fr
+}
+
+// WithExecFunc sets function computing result of INSERT, UPDATE or DELETE on every trigger
+//
+//	WithExecFunc(func(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+//		return NewFakeResult(args[0].Value.(int64), 1), nil
+//	})
+func (fr *FakeResponse) WithExecFunc(f ExecFunc) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.ExecFunc = f
+	return fr
+}
@@ -0,0 +1,128 @@
+package gomocket
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+)
+
+// Reply sets reply built from typed model structs, see WithReplyStructs for columns mapping
+// example: mocket.Reply(Catcher.NewMock().WithQuery(`SELECT * FROM users`), []User{{ID: 1}})
+func Reply[T any](fr *FakeResponse, items []T) *FakeResponse {
+	return fr.WithReplyStructs(items)
+}
+
+// Rows converts typed model structs to map rows, e.g. to be used with WithReplies
+// example: WithReplies(mocket.Rows(firstPage...), mocket.Rows(secondPage...))
+func Rows[T any](items ...T) []map[string]interface{} {
+	fields := structColumns(structType[T](), nil, "")
+	rows := make([]map[string]interface{}, 0, len(items))
+	for i := range items {
+		item := reflect.ValueOf(&items[i]).Elem()
+		record := make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			record[f.column] = fieldValue(item, f.index)
+		}
+		rows = append(rows, record)
+	}
+	return rows
+}
+
+// CallsOf decodes args of every call which triggered the mock back into model structs.
+// Args are bound to columns by INSERT column list, column = ? comparisons or :name placeholders,
+// multi-row INSERT gives a struct per row. When nothing is bound, args fill fields in order.
+// example: users := mocket.CallsOf[User](insertMock)
+func CallsOf[T any](fr *FakeResponse) []T {
+	Catcher.mu.RLock()
+	var calls []receivedCall
+	for _, call := range Catcher.calls {
+		if call.mock == fr {
+			calls = append(calls, call)
+		}
+	}
+	Catcher.mu.RUnlock()
+
+	fields := structColumns(structType[T](), nil, "")
+	var result []T
+	for _, call := range calls {
+		// Args are bound without the lock, lexer reads dialect of Catcher
+		records := argBindings(call.statement, call.args)
+		if records == nil {
+			records = []map[string]interface{}{positionalRecord(fields, call.args)}
+		}
+		for _, record := range records {
+			var item T
+			v := reflect.ValueOf(&item).Elem()
+			for _, f := range fields {
+				if value, ok := record[f.column]; ok {
+					setField(v, f.index, value)
+				}
+			}
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// structType returns struct type of T, which could be a pointer to struct
+func structType[T any]() reflect.Type {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// positionalRecord binds args to columns in order of struct fields
+func positionalRecord(fields []structColumn, args []driver.NamedValue) map[string]interface{} {
+	record := make(map[string]interface{}, len(args))
+	for i, arg := range args {
+		if i < len(fields) {
+			record[fields[i].column] = arg.Value
+		}
+	}
+	return record
+}
+
+// setField sets value to the field by index allocating nil pointers on the way, values which
+// could not be converted to the field type are skipped
+func setField(v reflect.Value, index []int, value interface{}) {
+	for _, i := range index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	if !v.CanSet() {
+		return
+	}
+	if scanner, ok := v.Addr().Interface().(sql.Scanner); ok {
+		scanner.Scan(value)
+		return
+	}
+	if value == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+	target := v
+	if v.Kind() == reflect.Ptr {
+		target = reflect.New(v.Type().Elem()).Elem()
+	}
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.Type().AssignableTo(target.Type()):
+		target.Set(rv)
+	case rv.Type().ConvertibleTo(target.Type()) && rv.Kind() != reflect.String && target.Kind() != reflect.String:
+		target.Set(rv.Convert(target.Type()))
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 && target.Kind() == reflect.String:
+		target.SetString(string(rv.Bytes()))
+	default:
+		return
+	}
+	if v.Kind() == reflect.Ptr {
+		v.Set(target.Addr())
+	}
+}
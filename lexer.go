@@ -0,0 +1,215 @@
+package gomocket
+
+import (
+	"strconv"
+	"strings"
+)
+
+// sqlTokenKind is a kind of lexical part of SQL query
+type sqlTokenKind int
+
+const (
+	tokenText        sqlTokenKind = iota // Keywords, identifiers, numbers, operators and whitespaces
+	tokenString                          // 'string literal'
+	tokenQuotedIdent                     // "identifier" or `identifier`
+	tokenComment                         // -- line or /* block */ comment
+	tokenPlaceholder                     // ?, $1, :name or @p1
+	tokenSemicolon                       // ; between statements
+)
+
+// placeholderStyle is a notation of bind parameter
+type placeholderStyle int
+
+const (
+	placeholderQuestion placeholderStyle = iota // ? used by MySQL and SQLite
+	placeholderDollar                           // $1 used by Postgres
+	placeholderColon                            // :name used by Oracle and sqlx
+	placeholderAt                               // @p1 used by SQL Server
+)
+
+// sqlToken is a lexical part of SQL query
+type sqlToken struct {
+	kind    sqlTokenKind
+	text    string
+	style   placeholderStyle // Notation of placeholder
+	name    string           // Name of :name placeholder
+	ordinal int              // 1-based position of bound arg for placeholders, same for repeated references
+}
+
+// lexSQL splits query into tokens, string literals, quoted identifiers and comments are never
+// looked into, so placeholders and semicolons inside them are not recognised. Backslash escapes
+// quote only in MySQL strings and Postgres E'...' strings, standard SQL strings end at the first quote.
+func lexSQL(query string, dialect Dialect) []sqlToken {
+	var tokens []sqlToken
+	textStart := 0
+	questions := 0
+	names := make(map[string]int)
+
+	flushText := func(end int) {
+		if end > textStart {
+			tokens = append(tokens, sqlToken{kind: tokenText, text: query[textStart:end]})
+		}
+	}
+	emit := func(start, end int, t sqlToken) int {
+		flushText(start)
+		t.text = query[start:end]
+		tokens = append(tokens, t)
+		textStart = end
+		return end
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			backslash := dialect == DialectMySQL ||
+				(i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && (i == 1 || !isIdentChar(query[i-2])))
+			i = emit(i, scanQuoted(query, i, '\'', backslash), sqlToken{kind: tokenString})
+		case c == '"' || c == '`':
+			i = emit(i, scanQuoted(query, i, c, false), sqlToken{kind: tokenQuotedIdent})
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query)
+			} else {
+				end += i + 1
+			}
+			i = emit(i, end, sqlToken{kind: tokenComment})
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query)
+			} else {
+				end += i + 4
+			}
+			i = emit(i, end, sqlToken{kind: tokenComment})
+		case c == ';':
+			i = emit(i, i+1, sqlToken{kind: tokenSemicolon})
+		case c == '?':
+			questions++
+			i = emit(i, i+1, sqlToken{kind: tokenPlaceholder, style: placeholderQuestion, ordinal: questions})
+		case c == '$':
+			if end := scanDigits(query, i+1); end > i+1 {
+				n, _ := strconv.Atoi(query[i+1 : end])
+				i = emit(i, end, sqlToken{kind: tokenPlaceholder, style: placeholderDollar, ordinal: n})
+			} else if end := scanDollarQuoted(query, i); end > i {
+				i = emit(i, end, sqlToken{kind: tokenString})
+			} else {
+				i++
+			}
+		case c == ':':
+			// :: is a Postgres type cast, := is an assignment
+			if i+1 < len(query) && query[i+1] == ':' {
+				i += 2
+				continue
+			}
+			if i > 0 && isIdentChar(query[i-1]) {
+				i++
+				continue
+			}
+			end := scanIdent(query, i+1)
+			if end == i+1 {
+				i++
+				continue
+			}
+			name := query[i+1 : end]
+			ordinal, ok := names[name]
+			if !ok {
+				ordinal = len(names) + 1
+				names[name] = ordinal
+			}
+			i = emit(i, end, sqlToken{kind: tokenPlaceholder, style: placeholderColon, name: name, ordinal: ordinal})
+		case c == '@' && i+2 < len(query) && (query[i+1] == 'p' || query[i+1] == 'P'):
+			end := scanDigits(query, i+2)
+			if end == i+2 || (end < len(query) && isIdentChar(query[end])) {
+				i++
+				continue
+			}
+			n, _ := strconv.Atoi(query[i+2 : end])
+			i = emit(i, end, sqlToken{kind: tokenPlaceholder, style: placeholderAt, ordinal: n})
+		default:
+			i++
+		}
+	}
+	flushText(len(query))
+	return tokens
+}
+
+// countInputs returns how many args are required by placeholders, repeated references are counted once
+func countInputs(tokens []sqlToken) int {
+	questions, numbered := 0, 0
+	names := make(map[string]bool)
+	for _, t := range tokens {
+		if t.kind != tokenPlaceholder {
+			continue
+		}
+		switch t.style {
+		case placeholderQuestion:
+			questions++
+		case placeholderColon:
+			names[t.name] = true
+		default:
+			if t.ordinal > numbered {
+				numbered = t.ordinal
+			}
+		}
+	}
+	return questions + numbered + len(names)
+}
+
+// scanQuoted returns end of literal started with quote at start, doubled quote is an escaped one
+func scanQuoted(query string, start int, quote byte, backslash bool) int {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// scanDollarQuoted returns end of Postgres $tag$ string $tag$ started at start or start if there is no such string
+func scanDollarQuoted(query string, start int) int {
+	tagEnd := scanIdent(query, start+1)
+	if tagEnd >= len(query) || query[tagEnd] != '$' {
+		return start
+	}
+	tag := query[start : tagEnd+1]
+	end := strings.Index(query[tagEnd+1:], tag)
+	if end < 0 {
+		return len(query)
+	}
+	return tagEnd + 1 + end + len(tag)
+}
+
+func scanDigits(query string, start int) int {
+	i := start
+	for i < len(query) && isDigit(query[i]) {
+		i++
+	}
+	return i
+}
+
+func scanIdent(query string, start int) int {
+	i := start
+	for i < len(query) && isIdentChar(query[i]) {
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
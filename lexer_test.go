@@ -0,0 +1,84 @@
+package gomocket
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestCountInputs(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected int
+		dialect  Dialect
+	}{
+		{`SELECT * FROM users`, 0, DialectDefault},
+		{`SELECT * FROM users WHERE a = ? AND b = ?`, 2, DialectDefault},
+		{`SELECT * FROM users WHERE a = ? AND b = 'what?'`, 1, DialectDefault},
+		{`SELECT * FROM users WHERE a = 'it''s ?' AND b = ?`, 1, DialectDefault},
+		{`SELECT "col?" FROM users -- really?` + "\n" + `WHERE a = ?`, 1, DialectDefault},
+		{`SELECT * /* ? */ FROM users WHERE a = ?`, 1, DialectDefault},
+		{`SELECT * FROM users WHERE a = $1 AND b = $2 OR a = $1`, 2, DialectDefault},
+		{`SELECT $body$ $1 ? $body$, $1::int FROM users`, 1, DialectDefault},
+		{`SELECT * FROM users WHERE email = :email AND (:email IS NULL OR id = :id)`, 2, DialectDefault},
+		{`SELECT '10:30', a::text FROM users WHERE id = :id`, 1, DialectDefault},
+		{`SELECT * FROM users WHERE a = @p1 AND b = @p2 AND c = @p1 AND @pname = 1`, 2, DialectDefault},
+		{`SELECT * FROM files WHERE p = 'C:\' AND id = ?`, 1, DialectDefault},
+		{`SELECT * FROM files WHERE p = 'C:\' AND id = ?`, 1, DialectPostgres},
+		{`SELECT * FROM files WHERE p = E'it\'s ?' AND id = $1`, 1, DialectPostgres},
+		{`SELECT * FROM files WHERE p = 'it\'s ?' AND id = ?`, 1, DialectMySQL},
+	}
+	for _, c := range cases {
+		if got := countInputs(lexSQL(c.query, c.dialect)); got != c.expected {
+			t.Errorf("Query %q should have %d inputs. Got %d", c.query, c.expected, got)
+		}
+	}
+}
+
+func TestArgsCountMismatch(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+	Catcher.Reset().NewMock().WithQuery(`SELECT name FROM users`)
+
+	if _, err := db.Query(`SELECT name FROM users WHERE name = 'who?' AND age = ?`, 27); err != nil {
+		t.Errorf("Question mark in literal should not be counted. Got %v", err)
+	}
+	if _, err := db.Query(`SELECT name FROM users WHERE age = ?`); err == nil {
+		t.Errorf("Missing argument should be reported")
+	}
+}
+
+func TestCompleteStatement(t *testing.T) {
+	moment := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		query    string
+		args     []driver.NamedValue
+		dialect  Dialect
+		expected string
+	}{
+		{`SELECT * FROM users WHERE name = ? AND note = 'why?' AND age = ?`,
+			[]driver.NamedValue{{Ordinal: 1, Value: "O'Brien"}, {Ordinal: 2, Value: int64(27)}}, DialectDefault,
+			`SELECT * FROM users WHERE name = 'O''Brien' AND note = 'why?' AND age = 27`},
+		{`SELECT * FROM users WHERE a = $2 AND b = $1 AND c = $2`,
+			[]driver.NamedValue{{Ordinal: 1, Value: true}, {Ordinal: 2, Value: nil}}, DialectPostgres,
+			`SELECT * FROM users WHERE a = NULL AND b = TRUE AND c = NULL`},
+		{`SELECT * FROM users WHERE email = :email AND id = :id`,
+			[]driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(1)}, {Name: "email", Ordinal: 2, Value: "a@b.c"}}, DialectDefault,
+			`SELECT * FROM users WHERE email = 'a@b.c' AND id = 1`},
+		{`INSERT INTO files VALUES (@p1, @p2, @p3)`,
+			[]driver.NamedValue{{Ordinal: 1, Value: []byte{0xca, 0xfe}}, {Ordinal: 2, Value: false}, {Ordinal: 3, Value: moment}}, DialectSQLServer,
+			`INSERT INTO files VALUES (0xCAFE, 0, '2020-01-02T03:04:05')`},
+		{`INSERT INTO files VALUES (?, ?, ?)`,
+			[]driver.NamedValue{{Ordinal: 1, Value: []byte{0xca, 0xfe}}, {Ordinal: 2, Value: `C:\`}, {Ordinal: 3, Value: moment}}, DialectMySQL,
+			`INSERT INTO files VALUES (X'CAFE', 'C:\\', '2020-01-02 03:04:05')`},
+		{`INSERT INTO files VALUES ($1, $2)`,
+			[]driver.NamedValue{{Ordinal: 1, Value: []byte{0xca, 0xfe}}, {Ordinal: 2, Value: moment}}, DialectPostgres,
+			`INSERT INTO files VALUES ('\xcafe', '2020-01-02 03:04:05+00:00')`},
+	}
+	for _, c := range cases {
+		if got := completeStatement(c.query, c.args, c.dialect); got != c.expected {
+			t.Errorf("Query %q is completed wrong.\nExpected: %s\nGot:      %s", c.query, c.expected, got)
+		}
+	}
+}
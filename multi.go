@@ -0,0 +1,121 @@
+package gomocket
+
+import (
+	"database/sql/driver"
+	"sort"
+	"strings"
+)
+
+// SetSplitStatements turns on splitting of multi-statement queries like "UPDATE ...; INSERT ...;"
+// by top-level semicolons, every statement is matched with mocks separately
+func (mc *MockCatcher) SetSplitStatements(split bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.SplitStatements = split
+}
+
+func (mc *MockCatcher) splitStatements() bool {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.SplitStatements
+}
+
+// splitStatements splits query into chain of statements, nil if there is only one statement
+func splitStatements(c *FakeConn, tokens []sqlToken) *FakeStmt {
+	var head, tail *FakeStmt
+	start := 0
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) && tokens[i].kind != tokenSemicolon {
+			continue
+		}
+		if st := newPartStmt(c, tokens[start:i]); st != nil {
+			if head == nil {
+				head = st
+			} else {
+				tail.next = st
+			}
+			tail = st
+		}
+		start = i + 1
+	}
+	if head == nil || head.next == nil {
+		return nil
+	}
+	return head
+}
+
+// newPartStmt creates statement from tokens, nil if there is nothing but comments and whitespaces
+func newPartStmt(c *FakeConn, tokens []sqlToken) *FakeStmt {
+	var b strings.Builder
+	command := ""
+	ordinals := make(map[int]bool)
+	names := make(map[string]bool)
+	var order []int
+	renumber := false
+	for _, t := range tokens {
+		if t.kind == tokenComment {
+			continue
+		}
+		if command == "" && t.kind == tokenText && strings.TrimSpace(t.text) != "" {
+			command = strings.ToUpper(strings.Fields(t.text)[0])
+		}
+		b.WriteString(t.text)
+		if t.kind == tokenPlaceholder {
+			if !ordinals[t.ordinal] {
+				order = append(order, t.ordinal)
+			}
+			ordinals[t.ordinal] = true
+			names[t.name] = t.style == placeholderColon
+			renumber = renumber || t.style == placeholderQuestion || t.style == placeholderColon
+		}
+	}
+	query := strings.TrimSpace(b.String())
+	if query == "" {
+		return nil
+	}
+	st := &FakeStmt{q: query, connection: c, command: command, renumber: renumber, argOrder: order}
+	for ordinal := range ordinals {
+		st.argOrdinals = append(st.argOrdinals, ordinal)
+	}
+	sort.Ints(st.argOrdinals)
+	for name, named := range names {
+		if named {
+			st.argNames = append(st.argNames, name)
+		}
+	}
+	sort.Strings(st.argNames)
+	return st
+}
+
+// statementArgs picks args used by statement of multi-statement query.
+// Named args are picked by names of :name placeholders, others by ordinals. Args of ? and :name
+// placeholders get ordinals they have in the statement alone, e.g. :y of "SET a = :x; SET b = :y" gets 1.
+func (s *FakeStmt) statementArgs(args []driver.NamedValue) []driver.NamedValue {
+	picked := make([]driver.NamedValue, 0, len(s.argOrdinals))
+	for _, arg := range args {
+		if arg.Name != "" {
+			if i := sort.SearchStrings(s.argNames, arg.Name); i < len(s.argNames) && s.argNames[i] == arg.Name {
+				picked = append(picked, arg)
+			}
+			continue
+		}
+		if i := sort.SearchInts(s.argOrdinals, arg.Ordinal); i < len(s.argOrdinals) && s.argOrdinals[i] == arg.Ordinal {
+			picked = append(picked, arg)
+		}
+	}
+	if s.renumber {
+		for i := range picked {
+			if picked[i].Name != "" {
+				continue // Named args are looked up by names
+			}
+			for local, ordinal := range s.argOrder {
+				if ordinal == picked[i].Ordinal {
+					picked[i].Ordinal = local + 1
+					break
+				}
+			}
+		}
+		sort.SliceStable(picked, func(i, j int) bool { return picked[i].Ordinal < picked[j].Ordinal })
+	}
+	return picked
+}
@@ -0,0 +1,142 @@
+package gomocket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InOrder expects mocks to be triggered in the given order, e.g. audit INSERT after UPDATE.
+// Every mock should be triggered and none of them could be triggered after the next one was.
+func (mc *MockCatcher) InOrder(mocks ...*FakeResponse) *MockCatcher {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	group := make([]*FakeResponse, len(mocks))
+	copy(group, mocks)
+	mc.orders = append(mc.orders, group)
+	return mc
+}
+
+// Transaction markers are journaled by FakeTx, they trigger only mocks with exactly this pattern
+const (
+	MarkerCommit   = "COMMIT"
+	MarkerRollback = "ROLLBACK"
+)
+
+// journalMarker records COMMIT or ROLLBACK of transaction and triggers mock registered with the same pattern,
+// so ordered groups could check e.g. audit INSERT after UPDATE and before COMMIT.
+// Markers never hit catch-all mocks and are not reported as queries without mock.
+func (mc *MockCatcher) journalMarker(marker string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.sortMocks()
+
+	for _, resp := range mc.Mocks {
+		if resp.marker() != marker || !mc.isScenarioMatch(resp) {
+			continue
+		}
+		if resp.takeCall() {
+			mc.journal(marker, marker, marker, nil, resp)
+			resp.MarkAsTriggered()
+			mc.moveScenario(resp)
+			resp.mu.Lock()
+			resp.TriggeredTimes++
+			resp.mu.Unlock()
+			return
+		}
+	}
+	mc.journal(marker, marker, marker, nil, nil)
+}
+
+// marker returns transaction marker the mock is registered for, empty for mocks of queries
+func (fr *FakeResponse) marker() string {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	switch pattern := strings.ToUpper(strings.TrimSpace(fr.Pattern)); pattern {
+	case MarkerCommit, MarkerRollback:
+		return pattern
+	}
+	return ""
+}
+
+// isMarker returns true if the call is COMMIT or ROLLBACK of transaction
+func (call receivedCall) isMarker() bool {
+	return call.args == nil && (call.statement == MarkerCommit || call.statement == MarkerRollback)
+}
+
+// SetStrictOrder sets all triggered mocks to be expected in order they were registered
+func (mc *MockCatcher) SetStrictOrder(strict bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.StrictOrder = strict
+}
+
+// ExpectationOfOrderIsMeet checks mocks of InOrder groups and in strict order mode all mocks were triggered in order
+func (mc *MockCatcher) ExpectationOfOrderIsMeet() (bool, []string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	msgs := []string{}
+	for _, group := range mc.orders {
+		if msg := mc.checkOrder(group, true); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	if mc.StrictOrder {
+		if msg := mc.checkOrder(mc.registered, false); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	return len(msgs) == 0, msgs
+}
+
+// checkOrder returns failure message if calls of group mocks are out of order, empty if they are in order
+func (mc *MockCatcher) checkOrder(group []*FakeResponse, all bool) string {
+	positions := make(map[*FakeResponse]int, len(group))
+	for i, fr := range group {
+		if _, ok := positions[fr]; !ok {
+			positions[fr] = i
+		}
+	}
+
+	var observed []receivedCall
+	triggered := make(map[*FakeResponse]bool)
+	inOrder, last := true, 0
+	for _, call := range mc.calls {
+		pos, ok := positions[call.mock]
+		if !ok {
+			continue
+		}
+		observed = append(observed, call)
+		triggered[call.mock] = true
+		if pos < last {
+			inOrder = false
+		}
+		last = pos
+	}
+	if all {
+		for _, fr := range group {
+			if !triggered[fr] {
+				inOrder = false
+			}
+		}
+	}
+	if inOrder {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("We are expecting mocks to be triggered in order:")
+	for i, fr := range group {
+		if all || triggered[fr] {
+			fmt.Fprintf(&b, "\n  %d. %s", i+1, describeMock(fr))
+		}
+	}
+	b.WriteString("\nbut got:")
+	if len(observed) == 0 {
+		b.WriteString("\n  nothing")
+	}
+	for _, call := range observed {
+		fmt.Fprintf(&b, "\n  %d. %s", positions[call.mock]+1, call.queryWithArgs)
+	}
+	return b.String()
+}
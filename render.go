@@ -0,0 +1,193 @@
+package gomocket
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// generatorState holds state of generators used by reply templates
+type generatorState struct {
+	seed     int64
+	seeded   bool
+	rand     *rand.Rand
+	counters map[string]int64
+	clock    func() time.Time
+}
+
+// reset starts sequences from scratch and restarts random generator from the seed
+func (g *generatorState) reset() {
+	g.counters = nil
+	g.rand = nil
+}
+
+// SetTemplateSeed makes {{uuid}} of reply templates reproducible and restarts {{seq}} counters
+func (mc *MockCatcher) SetTemplateSeed(seed int64) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.generators.seed = seed
+	mc.generators.seeded = true
+	mc.generators.reset()
+}
+
+// SetTemplateClock sets clock used by {{now}} of reply templates, time.Now by default
+func (mc *MockCatcher) SetTemplateClock(clock func() time.Time) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.generators.clock = clock
+}
+
+// nextSeq returns next value of named sequence starting from 1
+func (mc *MockCatcher) nextSeq(name string) int64 {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.generators.counters == nil {
+		mc.generators.counters = make(map[string]int64)
+	}
+	mc.generators.counters[name]++
+	return mc.generators.counters[name]
+}
+
+// newUUID returns random UUID version 4
+func (mc *MockCatcher) newUUID() string {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	g := &mc.generators
+	if g.rand == nil {
+		seed := g.seed
+		if !g.seeded {
+			seed = time.Now().UnixNano()
+		}
+		g.rand = rand.New(rand.NewSource(seed))
+	}
+	b := make([]byte, 16)
+	g.rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// now returns current time of template clock
+func (mc *MockCatcher) now() time.Time {
+	mc.mu.RLock()
+	clock := mc.generators.clock
+	mc.mu.RUnlock()
+	if clock == nil {
+		return time.Now()
+	}
+	return clock()
+}
+
+// Templated makes string values of reply evaluated as templates on every trigger:
+//
+//	{{arg 0}}                  - value of arg by index
+//	{{named "email"}}          - value of named arg or arg bound to the column
+//	{{match "LIMIT (\\d+)" 1}} - capture group of regexp applied to the query
+//	{{seq "users"}}            - next value of named sequence starting from 1
+//	{{uuid}}                   - random UUID, reproducible with Catcher.SetTemplateSeed
+//	{{now}}                    - current time of Catcher.SetTemplateClock
+//
+// Value consisting of a single action keeps type of its result, e.g. int64 of the arg.
+func (fr *FakeResponse) Templated() *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.IsTemplated = true
+	return fr
+}
+
+// renderSet evaluates templates of result set string values into its copy. Statement is the query with
+// placeholders used to bind args to columns, query has args put in place and is matched by regexps.
+func (mc *MockCatcher) renderSet(set *ReplySet, statement, query string, args []driver.NamedValue) (*ReplySet, error) {
+	set = set.clone()
+	for _, record := range set.Response {
+		for col, value := range record {
+			rendered, err := mc.renderValue(value, statement, query, args)
+			if err != nil {
+				return nil, err
+			}
+			record[col] = rendered
+		}
+	}
+	for _, values := range set.Rows {
+		for i, value := range values {
+			rendered, err := mc.renderValue(value, statement, query, args)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = rendered
+		}
+	}
+	return set, nil
+}
+
+// renderValue evaluates template of string value, other values are returned as they are
+func (mc *MockCatcher) renderValue(value interface{}, statement, query string, args []driver.NamedValue) (interface{}, error) {
+	text, ok := value.(string)
+	if !ok || !strings.Contains(text, "{{") {
+		return value, nil
+	}
+
+	var result interface{}
+	called := false
+	keep := func(v interface{}) interface{} {
+		result, called = v, true
+		return v
+	}
+	funcs := template.FuncMap{
+		"arg": func(i int) (interface{}, error) {
+			for _, arg := range args {
+				if arg.Ordinal == i+1 {
+					return keep(arg.Value), nil
+				}
+			}
+			return nil, fmt.Errorf("no arg %d", i)
+		},
+		"named": func(name string) (interface{}, error) {
+			for _, arg := range args {
+				if arg.Name == name {
+					return keep(arg.Value), nil
+				}
+			}
+			for _, record := range argBindings(statement, args) {
+				if v, ok := record[name]; ok {
+					return keep(v), nil
+				}
+			}
+			return nil, fmt.Errorf("no arg named %q", name)
+		},
+		"match": func(expr string, group int) (interface{}, error) {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, err
+			}
+			m := re.FindStringSubmatch(query)
+			if group >= len(m) {
+				return nil, fmt.Errorf("query does not match %q", expr)
+			}
+			return keep(m[group]), nil
+		},
+		"seq":  func(name string) interface{} { return keep(mc.nextSeq(name)) },
+		"uuid": func() interface{} { return keep(mc.newUUID()) },
+		"now":  func() interface{} { return keep(mc.now()) },
+	}
+	tpl, err := template.New("reply").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("mock_catcher: reply template %q: %v", text, err)
+	}
+	var b strings.Builder
+	if err := tpl.Execute(&b, nil); err != nil {
+		return nil, fmt.Errorf("mock_catcher: reply template %q: %v", text, err)
+	}
+	// Single action keeps type of its result
+	if nodes := tpl.Tree.Root.Nodes; len(nodes) == 1 && nodes[0].Type() == parse.NodeAction {
+		if called && (result == nil || b.String() == fmt.Sprint(result)) {
+			return result, nil
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,86 @@
+package gomocket
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// WithColumns starts tabular reply with fixed order of columns, duplicated names are allowed
+// example: WithColumns("id", "name").AddRow(1, "first").AddRow(2, "second")
+func (fr *FakeResponse) WithColumns(columns ...string) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.Columns = columns
+	fr.Rows = make([][]interface{}, 0)
+	fr.convertReplies()
+	return fr
+}
+
+// AddRow adds row to tabular reply, values go in order of columns
+func (fr *FakeResponse) AddRow(values ...interface{}) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	values = append([]interface{}(nil), values...)
+	if err := fr.convertRow(values, fr.Columns, -1, len(fr.Rows)); err != nil && fr.replyErr == nil {
+		fr.replyErr = err
+	}
+	fr.Rows = append(fr.Rows, values)
+	return fr
+}
+
+// replyRows converts result set of picked reply to columns and rows. Columns of map reply
+// go in order of selected columns when SELECT list of the query is known.
+func replyRows(result *ReplySet, selected []string) ([]string, []*row, error) {
+	if result.RowSource != nil {
+		if result.Columns == nil {
+			return nil, nil, errors.New("mock_catcher: columns of row source should be declared by WithColumns")
+		}
+		return result.Columns, nil, nil
+	}
+	if result.Columns != nil {
+		rows := make([]*row, 0, len(result.Rows))
+		for i, values := range result.Rows {
+			if len(values) != len(result.Columns) {
+				return nil, nil, fmt.Errorf("mock_catcher: row %d has %d values, but %d columns declared", i, len(values), len(result.Columns))
+			}
+			oneRow := &row{cols: make([]interface{}, len(values))}
+			copy(oneRow.cols, values)
+			rows = append(rows, oneRow)
+		}
+		return result.Columns, rows, nil
+	}
+
+	columnNames := mapColumns(result.Response)
+	if selected != nil {
+		columnNames = orderBySelect(columnNames, selected)
+	}
+	rows := make([]*row, 0, len(result.Response))
+	for _, record := range result.Response {
+		oneRow := &row{cols: make([]interface{}, len(columnNames))}
+		for i, col := range columnNames {
+			oneRow.cols[i] = record[col]
+		}
+		rows = append(rows, oneRow)
+	}
+	return columnNames, rows, nil
+}
+
+// mapColumns collects column names from all records of map reply. Columns go in order of the first
+// record they appear in, names first appearing in the same record are sorted, so order is stable between runs.
+func mapColumns(response []map[string]interface{}) []string {
+	columnNames := make([]string, 0, 1)
+	seen := make(map[string]bool)
+	for _, record := range response {
+		added := make([]string, 0, len(record))
+		for colName := range record {
+			if !seen[colName] {
+				seen[colName] = true
+				added = append(added, colName)
+			}
+		}
+		sort.Strings(added)
+		columnNames = append(columnNames, added...)
+	}
+	return columnNames
+}
@@ -33,8 +33,18 @@ type MockCatcher struct {
 	ReceivedQueriesRWLock   sync.RWMutex
 	NoMatchingQueries       map[string]int // All queries that didn't match any mock
 	NoMatchingQueriesRWLock sync.RWMutex
-	Logging                 bool // Do we need to log what we catching?
-	PanicOnEmptyResponse    bool // If not response matches - do we need to panic?
+	Logging                 bool              // Do we need to log what we catching?
+	PanicOnEmptyResponse    bool              // If not response matches - do we need to panic?
+	WarnOnAmbiguous         bool              // Log warning when query matches several mocks with the same priority
+	WarnOnColumnMismatch    bool              // Log warning when reply columns do not match SELECT list of the query
+	Dialect                 Dialect           // How args are rendered in received queries
+	SplitStatements         bool              // Split multi-statement queries and match every statement separately
+	scenarios               map[string]string // Current states of scenarios by names
+	calls                   []receivedCall    // Journal of all received calls in order
+	StrictOrder             bool              // Mocks should be triggered in order they were registered
+	registered              []*FakeResponse   // All mocks in order they were registered
+	orders                  [][]*FakeResponse // Groups of mocks expected to be triggered in order
+	generators              generatorState    // State of reply template generators
 	mu                      sync.RWMutex
 }
 
@@ -61,17 +71,29 @@ func (mc *MockCatcher) Attach(fr []*FakeResponse) {
 	defer mc.mu.Unlock()
 	for _, r := range fr {
 		r.Pattern = normalize(r.Pattern)
+		r.mu.Lock()
+		r.convertReplies()
+		r.mu.Unlock()
 		mc.Mocks = append(mc.Mocks, r)
+		mc.registered = append(mc.registered, r)
 	}
 }
 
 // FindResponse finds suitable response by provided
 func (mc *MockCatcher) FindResponse(query string, args []driver.NamedValue) *FakeResponse {
+	fr, _ := mc.findResponse(query, query, args)
+	return fr
+}
+
+// findResponse finds suitable response and returns it together with the number of its trigger.
+// Statement is the query with placeholders as it was prepared, query is what patterns are matched with.
+func (mc *MockCatcher) findResponse(statement, query string, args []driver.NamedValue) (*FakeResponse, uint32) {
 	mc.mu.Lock()
 	defer mc.mu.Unlock()
+	statement = normalize(statement)
 	query = normalize(query)
 
-	query_with_args := completeStatement(query, args)
+	query_with_args := completeStatement(statement, args, mc.Dialect)
 
 	mc.ReceivedQueriesRWLock.Lock()
 	if times, ok := mc.ReceivedQueries[query_with_args]; ok {
@@ -81,27 +103,35 @@ func (mc *MockCatcher) FindResponse(query string, args []driver.NamedValue) *Fak
 	}
 	mc.ReceivedQueriesRWLock.Unlock()
 
-	sort.SliceStable(mc.Mocks, func(i, j int) bool {
-		mc.Mocks[i].mu.RLock()
-		mc.Mocks[j].mu.RLock()
-		defer mc.Mocks[i].mu.RUnlock()
-		defer mc.Mocks[j].mu.RUnlock()
-		if mc.Mocks[i].MatchPriority != mc.Mocks[j].MatchPriority {
-			return mc.Mocks[i].MatchPriority > mc.Mocks[j].MatchPriority
-		} else {
-			return len(mc.Mocks[i].Pattern) > len(mc.Mocks[j].Pattern)
-		}
-	})
+	mc.sortMocks()
 
-	for _, resp := range mc.Mocks {
-		if resp.IsMatch(query, args) {
-			if mc.Logging {
-				log.Printf("mock_catcher: [MATCHED QUERY]: %s matches mock {pattern: %s, args: %v}", query_with_args, resp.Pattern, resp.Args)
-			}
-			resp.MarkAsTriggered()
-			resp.TriggeredTimes++
-			return resp
+	// Every matching mock counts the call, even one won by a mock checked before it.
+	// Mocks with an exhausted or not yet reached calls budget give way to the next matching one.
+	picked := -1
+	for i, resp := range mc.Mocks {
+		if !resp.isQueryMatch(query) || !resp.isArgsMatch(args) || !mc.isScenarioMatch(resp) {
+			continue
+		}
+		if resp.takeCall() && picked < 0 {
+			picked = i
+		}
+	}
+	if picked >= 0 {
+		resp := mc.Mocks[picked]
+		if mc.Logging {
+			log.Printf("mock_catcher: [MATCHED QUERY]: %s matches mock {pattern: %s, args: %v}", query_with_args, resp.Pattern, resp.Args)
 		}
+		if mc.WarnOnAmbiguous {
+			mc.warnAmbiguous(query, query_with_args, args, picked)
+		}
+		mc.journal(statement, query, query_with_args, args, resp)
+		resp.MarkAsTriggered()
+		mc.moveScenario(resp)
+		resp.mu.Lock()
+		resp.TriggeredTimes++
+		trigger := resp.TriggeredTimes
+		resp.mu.Unlock()
+		return resp, trigger
 	}
 
 	mc.NoMatchingQueriesRWLock.Lock()
@@ -111,20 +141,41 @@ func (mc *MockCatcher) FindResponse(query string, args []driver.NamedValue) *Fak
 		mc.NoMatchingQueries[query_with_args] = 1
 	}
 	mc.NoMatchingQueriesRWLock.Unlock()
+	mc.journal(statement, query, query_with_args, args, nil)
 
-	if mc.Logging {
-		log.Printf("mock_catcher: [NO MATCHED QUERY]: %s doesn't match anything", query_with_args)
-	}
-
-	if mc.PanicOnEmptyResponse {
-		panic(fmt.Sprintf("No responses matches query %s ", query_with_args))
+	if mc.Logging || mc.PanicOnEmptyResponse {
+		report := mc.explain(query, query_with_args, args, true).String()
+		if suggestions := formatSuggestions(mc.suggest(query, SuggestionsLimit)); suggestions != "" {
+			report += "\n" + suggestions
+		}
+		if mc.Logging {
+			log.Printf("mock_catcher: [NO MATCHED QUERY]: %s doesn't match anything\n%s", query_with_args, report)
+		}
+		if mc.PanicOnEmptyResponse {
+			panic(fmt.Sprintf("No responses matches query %s \n%s", query_with_args, report))
+		}
 	}
 
 	// Let's have always dummy version of response
 	return &FakeResponse{
 		Response:   make([]map[string]interface{}, 0),
 		Exceptions: &Exceptions{},
-	}
+	}, 0
+}
+
+// sortMocks orders mocks by match priority and then by pattern length, keeping registration order for equal ones
+func (mc *MockCatcher) sortMocks() {
+	sort.SliceStable(mc.Mocks, func(i, j int) bool {
+		mc.Mocks[i].mu.RLock()
+		mc.Mocks[j].mu.RLock()
+		defer mc.Mocks[i].mu.RUnlock()
+		defer mc.Mocks[j].mu.RUnlock()
+		if mc.Mocks[i].MatchPriority != mc.Mocks[j].MatchPriority {
+			return mc.Mocks[i].MatchPriority > mc.Mocks[j].MatchPriority
+		} else {
+			return len(mc.Mocks[i].Pattern) > len(mc.Mocks[j].Pattern)
+		}
+	})
 }
 
 // NewMock creates new FakeResponse and return for chains of attachments
@@ -133,6 +184,7 @@ func (mc *MockCatcher) NewMock() *FakeResponse {
 	defer mc.mu.Unlock()
 	fr := &FakeResponse{Exceptions: &Exceptions{}, Response: make([]map[string]interface{}, 0)}
 	mc.Mocks = append(mc.Mocks, fr)
+	mc.registered = append(mc.registered, fr)
 	return fr
 }
 
@@ -143,17 +195,43 @@ func (mc *MockCatcher) ExpectationOfTriggeredTimesIsMeet() (bool, []string) {
 
 	msgs := []string{}
 	for _, resp := range mc.Mocks {
-		if resp.ExpectedTriggeredTimes == 0 {
-			continue
+		resp.mu.RLock()
+		var msg string
+		switch {
+		case resp.ExpectNever && resp.TriggeredTimes > 0:
+			msg = fmt.Sprintf("We are expecting %s to be never triggered, but got %d", resp.Pattern, resp.TriggeredTimes)
+		case resp.ExpectedTriggeredTimes != 0 && resp.ExpectedTriggeredTimes != resp.TriggeredTimes:
+			msg = fmt.Sprintf("We are expecting %s to be triggered %d times, but got %d", resp.Pattern, resp.ExpectedTriggeredTimes, resp.TriggeredTimes)
+		case resp.ExpectedAtLeast != 0 && resp.TriggeredTimes < resp.ExpectedAtLeast:
+			msg = fmt.Sprintf("We are expecting %s to be triggered at least %d times, but got %d", resp.Pattern, resp.ExpectedAtLeast, resp.TriggeredTimes)
+		case resp.ExpectedAtMost != 0 && resp.TriggeredTimes > resp.ExpectedAtMost:
+			msg = fmt.Sprintf("We are expecting %s to be triggered at most %d times, but got %d", resp.Pattern, resp.ExpectedAtMost, resp.TriggeredTimes)
 		}
-		if resp.ExpectedTriggeredTimes != resp.TriggeredTimes {
-			msgs = append(msgs, fmt.Sprintf("We are expecting %s to be triggered %d times, but got %d", resp.Pattern, resp.ExpectedTriggeredTimes, resp.TriggeredTimes))
+		resp.mu.RUnlock()
+		if msg != "" {
+			msgs = append(msgs, msg+mc.describeCalls(resp))
 		}
 	}
 
 	return len(msgs) == 0, msgs
 }
 
+// describeCalls lists received calls which triggered the mock
+func (mc *MockCatcher) describeCalls(fr *FakeResponse) string {
+	var b strings.Builder
+	for _, call := range mc.calls {
+		if call.mock != fr {
+			continue
+		}
+		values := make([]interface{}, len(call.args))
+		for i, arg := range call.args {
+			values[i] = arg.Value
+		}
+		fmt.Fprintf(&b, "\n  %s (args: %v)", call.queryWithArgs, values)
+	}
+	return b.String()
+}
+
 // FindReceivedQuery checks how many times the query has been sent
 func (mc *MockCatcher) FindReceivedQuery(query string) (ok bool, times int) {
 	mc.ReceivedQueriesRWLock.RLock()
@@ -183,6 +261,11 @@ func (mc *MockCatcher) Reset() *MockCatcher {
 	mc.Mocks = make([]*FakeResponse, 0)
 	mc.ReceivedQueries = make(map[string]int)
 	mc.NoMatchingQueries = make(map[string]int)
+	mc.scenarios = make(map[string]string)
+	mc.calls = nil
+	mc.registered = nil
+	mc.orders = nil
+	mc.generators.reset()
 	return mc
 }
 
@@ -199,15 +282,43 @@ type FakeResponse struct {
 	Strict                 bool                              // Strict SQL query pattern comparison or by strings.Contains()
 	Args                   []interface{}                     // List args to be matched with
 	Response               []map[string]interface{}          // Array of rows to be parsed as result
+	Columns                []string                          // Columns of tabular reply, take precedence over Response
+	Rows                   [][]interface{}                   // Rows of tabular reply, values go in order of Columns
+	ColumnTypes            map[string]ColumnType             // Declared types of reply columns, inferred from values if missing
 	Once                   bool                              // To trigger only once
+	MaxTriggeredTimes      uint32                            // How many times it could be triggered, 0 means unlimited
+	OnlyOnCall             uint32                            // Trigger only on this matching call (1-based), 0 means any
+	SkipCalls              uint32                            // How many matching calls to let through before triggering
 	Triggered              bool                              // If it was triggered at least once
 	ExpectedTriggeredTimes uint32                            // How many times we are expecting to be triggerd
+	ExpectedAtLeast        uint32                            // At least how many times we are expecting to be triggered, 0 means no limit
+	ExpectedAtMost         uint32                            // At most how many times we are expecting to be triggered, 0 means no limit
+	ExpectNever            bool                              // We are expecting it to be never triggered
 	TriggeredTimes         uint32                            // How many times that has been triggerd
+	MatchedTimes           uint32                            // How many queries matched pattern and args, including skipped and won by others
 	Callback               func(string, []driver.NamedValue) // Callback to execute when response triggered
 	RowsAffected           int64                             // Defines affected rows count
 	LastInsertID           int64                             // ID to be returned for INSERT queries
 	Error                  error                             // Any type of error which could happen dur
+	Replies                [][]map[string]interface{}        // Sequential responses, one per trigger, take precedence over Response
+	ReplySets              []*ReplySet                       // Several result sets of reply, take precedence over Response
+	RowError               error                             // Error returned by rows.Next() after RowErrorAfter rows
+	RowErrorAfter          int                               // How many rows are returned before RowError
+	RowSource              RowSource                         // Generator of tabular reply rows pulled lazily, takes precedence over Rows
+	RowsConsumed           uint64                            // How many rows of RowSource were read by callers
+	ReplyFunc              ReplyFunc                         // Computes reply of query, takes precedence over the rest of reply
+	ExecFunc               ExecFunc                          // Computes result of exec, takes precedence over RowsAffected and LastInsertID
+	IsTemplated            bool                              // String values of reply are templates evaluated on every trigger
+	Dataset                []map[string]interface{}          // Rows of table-like dataset filtered by WHERE clause of query
+	RowsAffectedSequence   []int64                           // Sequential affected rows counts, one per trigger
+	LastInsertIDSequence   []int64                           // Sequential insert IDs, one per trigger
+	Errors                 []error                           // Sequential errors, one per trigger, nil means no error
+	Scenario               string                            // Name of scenario the mock belongs to
+	RequiredScenarioState  string                            // State scenario should be in to match the mock, empty means any
+	NewScenarioState       string                            // State scenario moves to when the mock is triggered
+	ExhaustedPolicy        SequencePolicy                    // What to do when sequential values are used up
 	mu                     sync.RWMutex                      // Used to lock concurrent access to variables
+	replyErr               error                             // First reply value which could not be converted to driver.Value
 	*Exceptions
 }
 
@@ -244,14 +355,44 @@ func (fr *FakeResponse) isQueryMatch(query string) bool {
 	return false
 }
 
-// IsMatch checks if both query and args matcher's return true and if this is Once mock
-func (fr *FakeResponse) IsMatch(query string, args []driver.NamedValue) bool {
-	fr.mu.Lock()
+// callsBudgetViolation explains why the mock could not be triggered on the given matching call number, empty if it could
+func (fr *FakeResponse) callsBudgetViolation(call uint32) string {
 	if fr.Once && fr.Triggered {
-		fr.mu.Unlock()
+		return "once mock is already triggered"
+	}
+	if fr.MaxTriggeredTimes > 0 && fr.TriggeredTimes >= fr.MaxTriggeredTimes {
+		return fmt.Sprintf("times budget %d is exhausted", fr.MaxTriggeredTimes)
+	}
+	if fr.OnlyOnCall > 0 && call != fr.OnlyOnCall {
+		return fmt.Sprintf("waits for call %d, got call %d", fr.OnlyOnCall, call)
+	}
+	if call <= fr.SkipCalls {
+		return fmt.Sprintf("skips first %d calls, got call %d", fr.SkipCalls, call)
+	}
+	return ""
+}
+
+// isCallAllowed returns true if the mock could be triggered on the given matching call number
+func (fr *FakeResponse) isCallAllowed(call uint32) bool {
+	return fr.callsBudgetViolation(call) == ""
+}
+
+// takeCall counts one more matching call and reports if the mock could be triggered by it
+func (fr *FakeResponse) takeCall() bool {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.MatchedTimes++
+	return fr.isCallAllowed(fr.MatchedTimes)
+}
+
+// IsMatch checks if both query and args matcher's return true and if calls budget allows to trigger the mock
+func (fr *FakeResponse) IsMatch(query string, args []driver.NamedValue) bool {
+	fr.mu.RLock()
+	if !fr.isCallAllowed(fr.MatchedTimes + 1) {
+		fr.mu.RUnlock()
 		return false
 	}
-	fr.mu.Unlock()
+	fr.mu.RUnlock()
 	return fr.isQueryMatch(query) && fr.isArgsMatch(args)
 }
 
@@ -294,6 +435,7 @@ func (fr *FakeResponse) WithReply(response []map[string]interface{}) *FakeRespon
 	fr.mu.Lock()
 	defer fr.mu.Unlock()
 	fr.Response = response
+	fr.convertReplies()
 	return fr
 }
 
@@ -305,6 +447,31 @@ func (fr *FakeResponse) OneTime() *FakeResponse {
 	return fr
 }
 
+// Times limits how many times the mock could be triggered, after that other mocks are used
+// example: WithQuery("SELECT").WithQueryException().Times(2)
+func (fr *FakeResponse) Times(n uint32) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.MaxTriggeredTimes = n
+	return fr
+}
+
+// OnCall sets mock to be triggered only by the n-th matching call (starting from 1), other calls fall through
+func (fr *FakeResponse) OnCall(n uint32) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.OnlyOnCall = n
+	return fr
+}
+
+// AfterCalls lets first n matching calls fall through to other mocks and triggers the mock after that
+func (fr *FakeResponse) AfterCalls(n uint32) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.SkipCalls = n
+	return fr
+}
+
 // WithExecException says that if mock attached to non-SELECT query we need to trigger error there
 func (fr *FakeResponse) WithExecException() *FakeResponse {
 	fr.Exceptions.HookExecBadConnection = func() bool {
@@ -353,6 +520,45 @@ func (fr *FakeResponse) WithExpectedTriggerTimes(expected uint32) *FakeResponse
 	return fr
 }
 
+// Never sets expectation for mock to be never triggered
+func (fr *FakeResponse) Never() *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.ExpectNever = true
+	return fr
+}
+
+// AtLeast sets expectation for mock to be triggered at least n times
+func (fr *FakeResponse) AtLeast(n uint32) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.ExpectedAtLeast = n
+	return fr
+}
+
+// AtMost sets expectation for mock to be triggered at most n times, AtMost(0) is the same as Never()
+func (fr *FakeResponse) AtMost(n uint32) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.ExpectedAtMost = n
+	fr.ExpectNever = n == 0
+	return fr
+}
+
+// Between sets expectation for mock to be triggered from min to max times inclusively, panics if min > max
+// example: Between(1, 3)
+func (fr *FakeResponse) Between(min, max uint32) *FakeResponse {
+	if min > max {
+		panic(fmt.Sprintf("mock_catcher: Between expects min <= max, got %d > %d", min, max))
+	}
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.ExpectedAtLeast = min
+	fr.ExpectedAtMost = max
+	fr.ExpectNever = min == 0 && max == 0
+	return fr
+}
+
 // WithMatchPriority sets priority
 func (fr *FakeResponse) WithMatchPriority(priority int) *FakeResponse {
 	fr.MatchPriority = priority
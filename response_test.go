@@ -1,9 +1,16 @@
 package gomocket
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	"log"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 var DB *sql.DB
@@ -284,6 +291,40 @@ func TestResponses(t *testing.T) {
 		}
 	})
 
+	t.Run("Triggered Times ranges", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users WHERE`).WithReply(commonReply).Between(1, 2)
+		Catcher.NewMock().WithQuery(`DELETE FROM users`).Never()
+		atLeast := Catcher.NewMock().WithQuery(`SELECT name, age FROM users`).AtLeast(1)
+		GetUsers(DB)
+		meet, msgs := Catcher.ExpectationOfTriggeredTimesIsMeet()
+		if meet || len(msgs) != 1 || !strings.Contains(msgs[0], "at least 1 times") {
+			t.Fatalf("Only at least expectation should fail. Got %v", msgs)
+		}
+		atLeast.AtLeast(0)
+		GetUsers(DB)
+		GetUsers(DB)
+		DB.Exec(`DELETE FROM users WHERE id = ?`, 1)
+		meet, msgs = Catcher.ExpectationOfTriggeredTimesIsMeet()
+		if meet || len(msgs) != 2 {
+			t.Fatalf("Between and never expectations should fail. Got %v", msgs)
+		}
+		if !strings.Contains(msgs[0], "at most 2 times, but got 3") || strings.Count(msgs[0], "age=27") != 3 {
+			t.Errorf("Message should list all calls. Got %s", msgs[0])
+		}
+		if !strings.Contains(msgs[1], "never triggered") || !strings.Contains(msgs[1], "args: [1]") {
+			t.Errorf("Message should list calls with args. Got %s", msgs[1])
+		}
+	})
+
+	t.Run("Impossible range", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "min <= max") {
+				t.Errorf("Between with min > max should panic. Got %v", r)
+			}
+		}()
+		Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users WHERE`).Between(3, 1)
+	})
+
 	t.Run("Capture all queries", func(t *testing.T) {
 		Catcher.Logging = true
 		Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users WHERE`).WithReply(commonReply)
@@ -325,6 +366,1158 @@ func TestResponses(t *testing.T) {
 		}
 	})
 
+	t.Run("Calls budget", func(t *testing.T) {
+		t.Run("Times", func(t *testing.T) {
+			Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users WHERE`).WithReply(commonReply)
+			Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE age=27`).WithError(sql.ErrConnDone).Times(2)
+			for i := 0; i < 2; i++ {
+				if err := GetUsersWithError(DB); err == nil {
+					t.Fatalf("Error not triggered on call %d", i+1)
+				}
+			}
+			if result := GetUsers(DB); len(result) != 1 {
+				t.Fatalf("Returned sets is not equal to 1. Received %d", len(result))
+			}
+		})
+
+		t.Run("OnCall", func(t *testing.T) {
+			Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users WHERE`).WithReply(commonReply)
+			Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE age=27`).WithReply(commonReply2).OnCall(2)
+			ages := []string{}
+			for i := 0; i < 3; i++ {
+				ages = append(ages, GetUsers(DB)[0]["age"])
+			}
+			if ages[0] != "30" || ages[1] != "50" || ages[2] != "30" {
+				t.Errorf("Unexpected replies order %v", ages)
+			}
+		})
+
+		t.Run("OnCall behind higher priority", func(t *testing.T) {
+			commonReply3 := []map[string]interface{}{{"name": "FirstLast", "age": "70"}}
+			Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users WHERE`).WithReply(commonReply)
+			Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE age=27`).WithReply(commonReply3).Times(1).WithMatchPriority(5)
+			Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE age=27`).WithReply(commonReply2).OnCall(2)
+			ages := []string{}
+			for i := 0; i < 3; i++ {
+				ages = append(ages, GetUsers(DB)[0]["age"])
+			}
+			if ages[0] != "70" || ages[1] != "50" || ages[2] != "30" {
+				t.Errorf("Call won by higher priority mock should be counted. Got replies %v", ages)
+			}
+		})
+
+		t.Run("AfterCalls", func(t *testing.T) {
+			Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users WHERE`).WithReply(commonReply)
+			fr := Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE age=27`).WithReply(commonReply2).AfterCalls(1)
+			if age := GetUsers(DB)[0]["age"]; age != "30" {
+				t.Errorf("First call should fall through. Got %v", age)
+			}
+			if age := GetUsers(DB)[0]["age"]; age != "50" {
+				t.Errorf("Second call should be caught. Got %v", age)
+			}
+			if fr.MatchedTimes != 2 || fr.TriggeredTimes != 1 {
+				t.Errorf("Unexpected counters: matched %d, triggered %d", fr.MatchedTimes, fr.TriggeredTimes)
+			}
+		})
+	})
+
+}
+
+func TestSequentialReplies(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+	firstPage := []map[string]interface{}{{"name": "First", "age": "30"}, {"name": "Second", "age": "31"}}
+	secondPage := []map[string]interface{}{{"name": "Third", "age": "32"}}
+
+	t.Run("Repeat last", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users`).WithReplies(firstPage, secondPage)
+		for i, expected := range []int{2, 1, 1} {
+			if result := GetUsers(db); len(result) != expected {
+				t.Errorf("Call %d: returned sets is not equal to %d. Received %d", i+1, expected, len(result))
+			}
+		}
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users`).WithReplies(firstPage, secondPage).WhenExhausted(Cycle)
+		for i, expected := range []int{2, 1, 2, 1} {
+			if result := GetUsers(db); len(result) != expected {
+				t.Errorf("Call %d: returned sets is not equal to %d. Received %d", i+1, expected, len(result))
+			}
+		}
+	})
+
+	t.Run("Fail when exhausted", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users`).WithReplies(firstPage).WhenExhausted(FailWhenExhausted)
+		if err := GetUsersWithError(db); err != nil {
+			t.Fatalf("First call should succeed. Got %v", err)
+		}
+		if err := GetUsersWithError(db); err != ErrSequenceExhausted {
+			t.Fatalf("Second call should fail with ErrSequenceExhausted. Got %v", err)
+		}
+	})
+
+	t.Run("Exec sequences", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`INSERT INTO foo`).WithIDSequence(1, 2).WithErrorSequence(nil, nil, sql.ErrConnDone)
+		for _, expected := range []int64{1, 2} {
+			if id := InsertRecord(db); id != expected {
+				t.Errorf("Insert ID is not equal to %d. Got %d", expected, id)
+			}
+		}
+		if _, err := db.Exec(`INSERT INTO foo VALUES("bar", ?)`, "value"); err != sql.ErrConnDone {
+			t.Errorf("Third call should fail. Got %v", err)
+		}
+	})
+}
+
+func TestScenarios(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+	paid := []map[string]interface{}{{"name": "paid", "age": "1"}}
+	shipped := []map[string]interface{}{{"name": "shipped", "age": "2"}}
+
+	Catcher.Reset().NewMock().WithQuery(`UPDATE orders SET paid`).InScenario("orders").WillSetStateTo("paid")
+	Catcher.NewMock().WithQuery(`UPDATE orders SET shipped`).InScenario("orders").WhenScenarioStateIs("paid").WillSetStateTo("shipped")
+	Catcher.NewMock().WithQuery(`SELECT name, age FROM users`).InScenario("orders").WhenScenarioStateIs("paid").WithReply(paid)
+	Catcher.NewMock().WithQuery(`SELECT name, age FROM users`).InScenario("orders").WhenScenarioStateIs("shipped").WithReply(shipped)
+
+	if state := Catcher.ScenarioState("orders"); state != ScenarioStarted {
+		t.Fatalf("Scenario should be started. Got %s", state)
+	}
+	if result := GetUsers(db); len(result) != 0 {
+		t.Errorf("Nothing should match before payment. Received %d", len(result))
+	}
+	db.Exec(`UPDATE orders SET shipped = 1`)
+	if state := Catcher.ScenarioState("orders"); state != ScenarioStarted {
+		t.Errorf("Order can't be shipped before payment. Got %s", state)
+	}
+	db.Exec(`UPDATE orders SET paid = 1`)
+	if result := GetUsers(db); len(result) != 1 || result[0]["name"] != "paid" {
+		t.Errorf("Paid order is expected. Got %v", result)
+	}
+	db.Exec(`UPDATE orders SET shipped = 1`)
+	if result := GetUsers(db); len(result) != 1 || result[0]["name"] != "shipped" {
+		t.Errorf("Shipped order is expected. Got %v", result)
+	}
+	if state := Catcher.ScenarioState("orders"); state != "shipped" {
+		t.Errorf("Scenario should be shipped. Got %s", state)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	commonReply := []map[string]interface{}{{"name": "FirstLast", "age": "30"}}
+	generic := Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users`).WithReply(commonReply)
+	specific := Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE age=`).WithReply(commonReply)
+	byArgs := Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE age=`).WithArgs(int64(30)).WithReply(commonReply)
+	once := Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE age=`).OneTime().WithMatchPriority(TESTCASE)
+	once.MarkAsTriggered()
+
+	e := Catcher.Explain(`SELECT name, age FROM users WHERE age=?`, int64(27))
+	t.Log(e)
+	if e.Winner != specific {
+		t.Fatalf("Specific mock should win. Got %v", e.Winner)
+	}
+	if !strings.Contains(e.Reason, "longer pattern") {
+		t.Errorf("Winner should be picked by pattern length. Got %s", e.Reason)
+	}
+	for _, me := range e.Mocks {
+		switch me.Mock {
+		case generic:
+			if !me.Matched {
+				t.Errorf("Generic mock should match")
+			}
+		case byArgs:
+			if me.ArgsMatch || me.Matched {
+				t.Errorf("Mock with other args should not match")
+			}
+		case once:
+			if me.CallsAllowed || me.CallsReason == "" {
+				t.Errorf("Triggered once mock should not be allowed")
+			}
+		}
+	}
+	if specific.MatchedTimes != 0 || specific.TriggeredTimes != 0 {
+		t.Errorf("Explain should not trigger mocks")
+	}
+
+	// Args are put in place of placeholders the same way as for rows returning queries
+	withArg := Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE age=27`).WithReply(commonReply)
+	e = Catcher.Explain(`SELECT name, age FROM users WHERE age=?`, int64(27))
+	if e.Winner != withArg {
+		t.Errorf("Mock with arg in pattern should win. Got %v", e.Winner)
+	}
+	db, _ := sql.Open(DriverName, "connection_string")
+	if rows, err := db.Query(`SELECT name, age FROM users WHERE age=?`, 27); err == nil {
+		rows.Close()
+	}
+	if withArg.TriggeredTimes != 1 {
+		t.Errorf("Query should trigger the same mock as explained. Got %d triggers", withArg.TriggeredTimes)
+	}
+
+	// Statements returning no rows are matched as they are, the same way as db.Exec does
+	deleteOne := Catcher.NewMock().WithQuery(`DELETE FROM users WHERE id = 1`).WithRowsNum(1)
+	if e = Catcher.Explain(`DELETE FROM users WHERE id = ?`, int64(1)); e.Winner != nil {
+		t.Errorf("Exec statement should not match pattern with arg in place. Got %v", e.Winner)
+	}
+	if res, err := db.Exec(`DELETE FROM users WHERE id = ?`, 1); err != nil || deleteOne.TriggeredTimes != 0 {
+		t.Errorf("Exec should not trigger the mock either. Got %v, %d triggers", err, deleteOne.TriggeredTimes)
+	} else if n, _ := res.RowsAffected(); n != 0 {
+		t.Errorf("No rows should be affected. Got %d", n)
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	Catcher.Reset().NewMock().WithQuery(`SELECT * FROM users  WHERE`)
+	Catcher.NewMock().WithQuery(`DELETE FROM orders`)
+	Catcher.NewMock().WithQuery(`SELECT * FROM "users" WHERE "users"."id" = 3`).StrictMatch()
+
+	suggestions := Catcher.suggest(normalize(`SELECT * FROM "users" WHERE "users"."id" = 4`), 2)
+	if len(suggestions) != 2 {
+		t.Fatalf("Two suggestions are expected. Got %d", len(suggestions))
+	}
+	if suggestions[0].Distance != 1 || suggestions[0].Diff != `SELECT * FROM "users" WHERE "users"."id" = [-3-]{+4+}` {
+		t.Errorf("Unexpected closest suggestion %+v", suggestions[0])
+	}
+	if suggestions[1].Distance != 2 || suggestions[1].Diff != `SELECT * FROM {+"+}users{+"+} WHERE` {
+		t.Errorf("Unexpected second suggestion %+v", suggestions[1])
+	}
+
+	t.Run("Attached to panic", func(t *testing.T) {
+		Catcher.PanicOnEmptyResponse = true
+		defer func() {
+			Catcher.PanicOnEmptyResponse = false
+			if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "closest patterns") {
+				t.Errorf("Panic should contain suggestions. Got %v", r)
+			}
+		}()
+		Catcher.FindResponse(`SELECT * FROM "users" WHERE "users"."id" = 4`, nil)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+	commonReply := []map[string]interface{}{{"name": "FirstLast", "age": "30"}}
+
+	generic := Catcher.Reset().NewMock().WithQuery(`SELECT name`).WithReply(commonReply).WithMatchPriority(TESTCASE)
+	shadowed := Catcher.NewMock().WithQuery(`SELECT name, age FROM users`).WithReply(commonReply)
+	first := Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE`).WithMatchPriority(TESTCASE)
+	second := Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE`).WithMatchPriority(TESTCASE).OneTime()
+	unused := Catcher.NewMock().WithQuery(`DELETE FROM users`)
+	never := Catcher.NewMock().WithQuery(`DROP TABLE users`).Never()
+	atMostZero := Catcher.NewMock().WithQuery(`TRUNCATE users`).AtMost(0)
+	GetUsers(db)
+
+	report := Catcher.Validate()
+	t.Log(report)
+	issues := map[string][]ValidationIssue{}
+	for _, issue := range report.Issues {
+		issues[issue.Kind] = append(issues[issue.Kind], issue)
+	}
+	shadowedBy := map[*FakeResponse]*FakeResponse{}
+	for _, issue := range issues[IssueShadowed] {
+		shadowedBy[issue.Mock] = issue.Other
+	}
+	if len(shadowedBy) != 2 || shadowedBy[shadowed] != generic || shadowedBy[second] != first {
+		t.Errorf("Shadowed mocks are not detected: %v", issues[IssueShadowed])
+	}
+	ambiguous := map[*FakeResponse]bool{}
+	for _, issue := range issues[IssueAmbiguous] {
+		ambiguous[issue.Other] = true
+	}
+	if !ambiguous[generic] || !ambiguous[second] {
+		t.Errorf("Ambiguous mocks are not detected: %v", issues[IssueAmbiguous])
+	}
+	unreachable := map[*FakeResponse]bool{}
+	for _, issue := range issues[IssueUnreachable] {
+		unreachable[issue.Mock] = true
+	}
+	if len(unreachable) != 4 || unreachable[first] || !unreachable[unused] || unreachable[never] || unreachable[atMostZero] {
+		t.Errorf("Unreachable mocks are not detected: %v", issues[IssueUnreachable])
+	}
+}
+
+func TestOrder(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	t.Run("In order", func(t *testing.T) {
+		update := Catcher.Reset().NewMock().WithQuery(`UPDATE users`)
+		audit := Catcher.NewMock().WithQuery(`INSERT INTO audit`)
+		notify := Catcher.NewMock().WithQuery(`INSERT INTO notifications`)
+		Catcher.InOrder(update, audit, notify)
+		db.Exec(`UPDATE users SET age = ?`, 27)
+		db.Exec(`INSERT INTO audit VALUES (?)`, "update")
+		if ok, _ := Catcher.ExpectationOfOrderIsMeet(); ok {
+			t.Errorf("Not triggered mock should fail the order")
+		}
+		db.Exec(`INSERT INTO notifications VALUES (?)`, "update")
+		if ok, msgs := Catcher.ExpectationOfOrderIsMeet(); !ok {
+			t.Errorf("Order should be meet. Got %v", msgs)
+		}
+		db.Exec(`INSERT INTO audit VALUES (?)`, "late")
+		ok, msgs := Catcher.ExpectationOfOrderIsMeet()
+		if ok || len(msgs) != 1 || !strings.Contains(msgs[0], "2. INSERT INTO audit VALUES ('late')") {
+			t.Errorf("Late audit should fail the order. Got %v", msgs)
+		}
+	})
+
+	t.Run("Strict order", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`UPDATE users`)
+		Catcher.NewMock().WithQuery(`INSERT INTO audit`)
+		Catcher.NewMock().WithQuery(`DELETE FROM sessions`)
+		Catcher.SetStrictOrder(true)
+		defer Catcher.SetStrictOrder(false)
+		db.Exec(`UPDATE users SET age = ?`, 27)
+		db.Exec(`DELETE FROM sessions WHERE id = ?`, 1)
+		if ok, msgs := Catcher.ExpectationOfOrderIsMeet(); !ok {
+			t.Errorf("Skipped mocks should not fail strict order. Got %v", msgs)
+		}
+		db.Exec(`INSERT INTO audit VALUES (?)`, "update")
+		if ok, _ := Catcher.ExpectationOfOrderIsMeet(); ok {
+			t.Errorf("Audit after delete should fail strict order")
+		}
+	})
+
+	t.Run("Transaction markers", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(``) // Catch-all mock is never triggered by markers
+		update := Catcher.NewMock().WithQuery(`UPDATE users`)
+		audit := Catcher.NewMock().WithQuery(`INSERT INTO audit`)
+		commit := Catcher.NewMock().WithQuery(MarkerCommit)
+		Catcher.InOrder(update, audit, commit)
+
+		tx, _ := db.Begin()
+		tx.Exec(`UPDATE users SET age = ?`, 27)
+		tx.Commit()
+		tx, _ = db.Begin()
+		tx.Exec(`INSERT INTO audit VALUES (?)`, "update")
+		tx.Rollback()
+		ok, msgs := Catcher.ExpectationOfOrderIsMeet()
+		if ok || len(msgs) != 1 || !strings.Contains(msgs[0], "3. COMMIT\n  2. INSERT INTO audit") {
+			t.Errorf("Audit after commit should fail the order. Got %v", msgs)
+		}
+		if commit.TriggeredTimes != 1 {
+			t.Errorf("Commit mock should be triggered once. Got %d", commit.TriggeredTimes)
+		}
+		for _, issue := range Catcher.Validate().Issues {
+			if issue.Mock == commit || strings.Contains(issue.Message, "COMMIT") {
+				t.Errorf("Markers should not cause validation issues. Got %s", issue.Message)
+			}
+		}
+	})
+}
+
+func TestTemplates(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+	commonReply := []map[string]interface{}{{"name": "FirstLast", "age": "30"}}
+	commonReply2 := []map[string]interface{}{{"name": "FirstLast", "age": "50"}}
+
+	t.Run("Clone", func(t *testing.T) {
+		original := Catcher.Reset().NewMock().WithQuery(`SELECT name, age FROM users WHERE`).WithReply(commonReply).OneTime()
+		GetUsers(db)
+		clone := original.Clone().WithQueryException()
+		if clone.Triggered || clone.TriggeredTimes != 0 || clone.MatchedTimes != 0 {
+			t.Errorf("Clone should have fresh trigger state")
+		}
+		if original.HookQueryBadConnection != nil {
+			t.Errorf("Hooks of the clone should not be shared with original")
+		}
+		clone.Response[0]["age"] = "40"
+		if original.Response[0]["age"] != "30" {
+			t.Errorf("Reply of the clone should not be shared with original")
+		}
+	})
+
+	t.Run("Template", func(t *testing.T) {
+		tpl := NewTemplate()
+		tpl.WithQuery(`SELECT name, age FROM users WHERE`).WithReply(commonReply).WithMatchPriority(TESTCASE)
+		Catcher.Reset().NewMockFrom(tpl)
+		derived := Catcher.NewMockFrom(tpl).WithArgs(int64(27)).WithReply(commonReply2)
+		if derived.Pattern != tpl.Pattern || derived.MatchPriority != TESTCASE {
+			t.Errorf("Pattern and priority should be inherited")
+		}
+		if result := GetUsers(db); len(result) != 1 || result[0]["age"] != "30" {
+			t.Errorf("Mock derived first should win. Got %v", result)
+		}
+		Catcher.Reset().NewMockFrom(tpl).WithArgs(int64(26))
+		Catcher.NewMockFrom(tpl).WithArgs(int64(27)).WithReply(commonReply2)
+		if result := GetUsers(db); len(result) != 1 || result[0]["age"] != "50" {
+			t.Errorf("Overridden reply should be returned. Got %v", result)
+		}
+	})
+}
+
+func TestMultiStatements(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+	Catcher.SetSplitStatements(true)
+	defer Catcher.SetSplitStatements(false)
+
+	t.Run("Exec", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`UPDATE users SET`).WithArgs(int64(27)).WithRowsNum(2)
+		Catcher.NewMock().WithQuery(`INSERT INTO audit`).WithArgs("update; done").WithID(64)
+		res, err := db.Exec("UPDATE users SET age = ?; -- bump age;\nINSERT INTO audit VALUES (?);", 27, "update; done")
+		if err != nil {
+			t.Fatalf("Multi-statement query failed [%v]", err)
+		}
+		if n, _ := res.RowsAffected(); n != 3 {
+			t.Errorf("Affected rows should be summed up. Got %d", n)
+		}
+		if id, _ := res.LastInsertId(); id != 64 {
+			t.Errorf("Insert ID is not returned. Got %d", id)
+		}
+		for _, query := range []string{`UPDATE users SET age = 27`, `INSERT INTO audit VALUES ('update; done')`} {
+			if _, times := Catcher.FindReceivedQuery(query); times != 1 {
+				t.Errorf("Statement %s should be received once", query)
+			}
+		}
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT name FROM users`).WithReply([]map[string]interface{}{{"name": "FirstLast"}})
+		Catcher.NewMock().WithQuery(`SELECT count FROM stats`).WithReply([]map[string]interface{}{{"count": int64(1)}, {"count": int64(2)}})
+		rows, err := db.Query(`SELECT name FROM users WHERE id = $1; SELECT count FROM stats WHERE day = $2`, 1, "today")
+		if err != nil {
+			t.Fatalf("Multi-statement query failed [%v]", err)
+		}
+		defer rows.Close()
+		counts := []int{}
+		for {
+			n := 0
+			for rows.Next() {
+				n++
+			}
+			counts = append(counts, n)
+			if !rows.NextResultSet() {
+				break
+			}
+		}
+		if len(counts) != 2 || counts[0] != 1 || counts[1] != 2 {
+			t.Errorf("Two result sets are expected. Got %v", counts)
+		}
+		if _, times := Catcher.FindReceivedQuery(`SELECT count FROM stats WHERE day = 'today'`); times != 1 {
+			t.Errorf("Second statement should be received with its args")
+		}
+	})
+
+	t.Run("Named placeholders", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`UPDATE users SET`).WithArgs(int64(1))
+		audit := Catcher.NewMock().WithQuery(`UPDATE audit SET`).WithArgs(int64(2), int64(1))
+		if _, err := db.Exec(`UPDATE users SET a = :x; UPDATE audit SET b = :y, c = :x`, 1, 2); err != nil {
+			t.Fatalf("Multi-statement query failed [%v]", err)
+		}
+		if _, times := Catcher.FindReceivedQuery(`UPDATE audit SET b = 2, c = 1`); times != 1 {
+			t.Errorf("Second statement should be received with its args")
+		}
+		type change struct {
+			B int64 `db:"b"`
+			C int64 `db:"c"`
+		}
+		if changes := CallsOf[change](audit); len(changes) != 1 || changes[0].B != 2 || changes[0].C != 1 {
+			t.Errorf("Args of second statement should be bound to its columns. Got %v", changes)
+		}
+	})
+}
+
+func TestTabularReply(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	t.Run("Fixed columns order", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT u.id, p.id FROM users`).WithColumns("id", "id").AddRow(int64(1), int64(10)).AddRow(int64(2), int64(20))
+		rows, err := db.Query(`SELECT u.id, p.id FROM users u JOIN profiles p ON p.user_id = u.id`)
+		if err != nil {
+			t.Fatalf("Query failed [%v]", err)
+		}
+		defer rows.Close()
+		sum := int64(0)
+		for rows.Next() {
+			var userID, profileID int64
+			if err := rows.Scan(&userID, &profileID); err != nil {
+				t.Fatalf("Scan failed [%v]", err)
+			}
+			if profileID != userID*10 {
+				t.Errorf("Columns are scanned out of order: %d, %d", userID, profileID)
+			}
+			sum += userID
+		}
+		if sum != 3 {
+			t.Errorf("Two rows are expected")
+		}
+	})
+
+	t.Run("Deterministic map columns", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT * FROM users`).WithReply([]map[string]interface{}{
+			{"name": "First", "age": "30"},
+			{"name": "Second", "id": 2, "email": "e"},
+		})
+		rows, _ := db.Query(`SELECT * FROM users`)
+		defer rows.Close()
+		columns, _ := rows.Columns()
+		if strings.Join(columns, ",") != "age,name,email,id" {
+			t.Errorf("Columns order is not deterministic. Got %v", columns)
+		}
+	})
+
+	t.Run("Wrong row size", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT * FROM users`).WithColumns("id", "name").AddRow(1)
+		if _, err := db.Query(`SELECT * FROM users`); err == nil {
+			t.Errorf("Row with missing values should fail the query")
+		}
+	})
+}
+
+func TestStructReply(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	type Model struct {
+		ID        int64 `gorm:"primaryKey;column:user_id"`
+		CreatedAt time.Time
+	}
+	type Author struct {
+		Name string
+	}
+	type User struct {
+		Model
+		FirstName string
+		Email     sql.NullString `db:"email_address"`
+		Age       *int
+		Author    Author `gorm:"embedded;embeddedPrefix:author_"`
+		Password  string `db:"-"`
+		internal  string
+	}
+	age := 30
+	users := []User{
+		{Model: Model{ID: 1}, FirstName: "First", Email: sql.NullString{String: "a@b.c", Valid: true}, Age: &age, Author: Author{Name: "Writer"}},
+		{Model: Model{ID: 2}, FirstName: "Second", internal: "skipped"},
+	}
+	Catcher.Reset().NewMock().WithQuery(`SELECT * FROM users`).WithReplyStructs(users)
+
+	rows, err := db.Query(`SELECT * FROM users`)
+	if err != nil {
+		t.Fatalf("Query failed [%v]", err)
+	}
+	defer rows.Close()
+	columns, _ := rows.Columns()
+	if strings.Join(columns, ",") != "user_id,created_at,first_name,email_address,age,author_name" {
+		t.Fatalf("Unexpected columns %v", columns)
+	}
+	result := []map[string]interface{}{}
+	for rows.Next() {
+		var id int64
+		var createdAt time.Time
+		var name, author string
+		var email sql.NullString
+		var userAge sql.NullInt64
+		if err := rows.Scan(&id, &createdAt, &name, &email, &userAge, &author); err != nil {
+			t.Fatalf("Scan failed [%v]", err)
+		}
+		result = append(result, map[string]interface{}{"id": id, "name": name, "email": email, "age": userAge, "author": author})
+	}
+	if len(result) != 2 || result[0]["email"].(sql.NullString).String != "a@b.c" || result[0]["age"].(sql.NullInt64).Int64 != 30 {
+		t.Errorf("Unexpected first row %v", result)
+	}
+	if result[1]["email"].(sql.NullString).Valid || result[1]["age"].(sql.NullInt64).Valid || result[0]["author"] != "Writer" {
+		t.Errorf("Unexpected second row %v", result)
+	}
+
+	type Ignored struct {
+		Skipped   string `gorm:"-"`
+		All       string `gorm:"-:all"`
+		Migration string `gorm:"-:migration"`
+	}
+	if fields := structColumns(reflect.TypeOf(Ignored{}), nil, ""); len(fields) != 1 || fields[0].column != "migration" {
+		t.Errorf("Only fields ignored by GORM completely should be skipped. Got %v", fields)
+	}
+}
+
+func TestGenericHelpers(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+	type User struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+		Age  *int   `db:"age"`
+	}
+
+	t.Run("Reply and Rows", func(t *testing.T) {
+		Catcher.Reset()
+		type Person struct {
+			Name string
+			Age  string
+		}
+		Reply(Catcher.NewMock().WithQuery(`SELECT name, age FROM users WHERE age=27`), []Person{{Name: "Typed", Age: "30"}})
+		Catcher.NewMock().WithQuery(`SELECT name, age FROM users`).WithReplies(Rows(User{Name: "First"}, User{Name: "Second"}))
+		if result := GetUsers(db); len(result) != 1 || result[0]["name"] != "Typed" {
+			t.Errorf("Typed reply is expected. Got %v", result)
+		}
+		rows, _ := db.Query(`SELECT name, age FROM users`)
+		defer rows.Close()
+		n := 0
+		for rows.Next() {
+			n++
+		}
+		if n != 2 {
+			t.Errorf("Two rows are expected. Got %d", n)
+		}
+	})
+
+	t.Run("CallsOf", func(t *testing.T) {
+		insert := Catcher.Reset().NewMock().WithQuery(`INSERT INTO "users"`)
+		update := Catcher.NewMock().WithQuery(`UPDATE users`)
+		db.Exec(`INSERT INTO "users" ("name", "age", "id") VALUES (?, ?, ?), (?, NULL, ?)`, "First", 30, 1, "Second", 2)
+		db.Exec(`UPDATE users SET name = $1 WHERE users.id = $2`, "Renamed", 1)
+
+		inserted := CallsOf[User](insert)
+		if len(inserted) != 2 || inserted[0].Name != "First" || *inserted[0].Age != 30 || inserted[1].ID != 2 || inserted[1].Age != nil {
+			t.Errorf("Unexpected inserted users %+v", inserted)
+		}
+		updated := CallsOf[*User](update)
+		if len(updated) != 1 || updated[0].Name != "Renamed" || updated[0].ID != 1 {
+			t.Errorf("Unexpected updated users %+v", updated)
+		}
+	})
+
+	t.Run("CallsOf rows returning queries", func(t *testing.T) {
+		insert := Catcher.Reset().NewMock().WithQuery(`INSERT INTO "users"`).WithID(7)
+		selectByName := Catcher.NewMock().WithQuery(`SELECT * FROM users WHERE name`)
+		var id int64
+		if err := db.QueryRow(`INSERT INTO "users" ("name") VALUES ($1) RETURNING "id"`, "First").Scan(&id); err != nil {
+			t.Fatalf("Query failed [%v]", err)
+		}
+		db.QueryRow(`SELECT * FROM users WHERE name = ?`, "Second").Scan(&id)
+
+		if inserted := CallsOf[User](insert); len(inserted) != 1 || inserted[0].Name != "First" {
+			t.Errorf("Unexpected inserted users %+v", inserted)
+		}
+		if selected := CallsOf[User](selectByName); len(selected) != 1 || selected[0].Name != "Second" {
+			t.Errorf("Unexpected selected users %+v", selected)
+		}
+	})
+}
+
+func TestColumnTypes(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	Catcher.Reset().NewMock().WithQuery(`SELECT * FROM products`).
+		WithColumns("id", "name", "price", "deleted_at").
+		AddRow(int64(1), "First", 9.99, nil).
+		AddRow(int64(2), "Second", 19.99, time.Now()).
+		WithColumnType("name", ColumnType{DatabaseTypeName: "VARCHAR", Length: 255}).
+		WithColumnType("price", ColumnType{DatabaseTypeName: "DECIMAL", Precision: 10, Scale: 2})
+	rows, err := db.Query(`SELECT * FROM products`)
+	if err != nil {
+		t.Fatalf("Query failed [%v]", err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("ColumnTypes failed [%v]", err)
+	}
+
+	if types[0].DatabaseTypeName() != "BIGINT" || types[0].ScanType().Kind() != reflect.Int64 {
+		t.Errorf("Type of id should be inferred. Got %s %v", types[0].DatabaseTypeName(), types[0].ScanType())
+	}
+	if length, ok := types[1].Length(); !ok || length != 255 {
+		t.Errorf("Declared length is expected. Got %d", length)
+	}
+	if precision, scale, ok := types[2].DecimalSize(); !ok || precision != 10 || scale != 2 {
+		t.Errorf("Declared precision and scale are expected. Got %d, %d", precision, scale)
+	}
+	if _, ok := types[0].Length(); ok {
+		t.Errorf("Length of not declared column should be unknown")
+	}
+	if nullable, ok := types[3].Nullable(); !ok || !nullable {
+		t.Errorf("Column with NULL values should be nullable")
+	}
+	if nullable, _ := types[1].Nullable(); nullable {
+		t.Errorf("Column without NULL values should not be nullable")
+	}
+	if types[3].DatabaseTypeName() != "TIMESTAMP" || types[3].ScanType() != reflect.TypeOf(sql.NullTime{}) {
+		t.Errorf("Nullable timestamp is expected. Got %s %v", types[3].DatabaseTypeName(), types[3].ScanType())
+	}
+
+	// Types of struct fields are declared, so all NULL columns keep them
+	type product struct {
+		ID    int64
+		Title *string
+		Note  sql.NullString
+	}
+	Catcher.Reset().NewMock().WithQuery(`SELECT * FROM products`).WithReplyStructs([]product{{ID: 1}, {ID: 2}})
+	structRows, err := db.Query(`SELECT * FROM products`)
+	if err != nil {
+		t.Fatalf("Query failed [%v]", err)
+	}
+	defer structRows.Close()
+	types, _ = structRows.ColumnTypes()
+	if types[1].DatabaseTypeName() != "VARCHAR" || types[1].ScanType() != reflect.TypeOf(sql.NullString{}) {
+		t.Errorf("Type of pointer field is expected. Got %s %v", types[1].DatabaseTypeName(), types[1].ScanType())
+	}
+	if types[2].DatabaseTypeName() != "" || types[2].ScanType() != reflect.TypeOf(sql.NullString{}) {
+		t.Errorf("Type of sql.NullString field is expected. Got %s %v", types[2].DatabaseTypeName(), types[2].ScanType())
+	}
+
+	// Nothing is known about streamed columns before rows are read
+	Catcher.Reset().NewMock().WithQuery(`SELECT * FROM events`).WithColumns("id").
+		WithRowSource(func(yield func([]driver.Value) bool) {})
+	streamRows, err := db.Query(`SELECT * FROM events`)
+	if err != nil {
+		t.Fatalf("Query failed [%v]", err)
+	}
+	defer streamRows.Close()
+	types, _ = streamRows.ColumnTypes()
+	if nullable, _ := types[0].Nullable(); types[0].DatabaseTypeName() != "" || !nullable {
+		t.Errorf("Streamed column should have unknown type and be nullable. Got %s", types[0].DatabaseTypeName())
+	}
+}
+
+func TestReplyValues(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	t.Run("Converted to driver values", func(t *testing.T) {
+		type Age int
+		Catcher.Reset().NewMock().WithQuery(`SELECT * FROM users`).WithReply([]map[string]interface{}{
+			{"age": Age(30), "name": sql.NullString{String: "First", Valid: true}, "email": sql.NullString{}},
+		})
+		rows, err := db.Query(`SELECT * FROM users`)
+		if err != nil {
+			t.Fatalf("Query failed [%v]", err)
+		}
+		defer rows.Close()
+		types, _ := rows.ColumnTypes()
+		if types[0].DatabaseTypeName() != "BIGINT" || types[2].DatabaseTypeName() != "VARCHAR" {
+			t.Errorf("Values should be converted to driver values. Got %s, %s", types[0].DatabaseTypeName(), types[2].DatabaseTypeName())
+		}
+		if nullable, _ := types[1].Nullable(); !nullable {
+			t.Errorf("Invalid sql.NullString should give NULL")
+		}
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		type Address struct{ City string }
+		mock := Catcher.Reset().NewMock().WithQuery(`SELECT * FROM users`).WithColumns("id", "address").
+			AddRow(1, "Main st").
+			AddRow(2, Address{City: "City"})
+		_, err := db.Query(`SELECT * FROM users`)
+		if err == nil {
+			t.Fatalf("Query should fail on invalid reply value")
+		}
+		var valueErr *ReplyValueError
+		if !errors.As(err, &valueErr) || valueErr.Mock != mock || valueErr.Row != 1 || valueErr.Column != "address" {
+			t.Errorf("Error should name mock, row and column. Got %v", err)
+		}
+		if report := Catcher.Validate(); report.OK() || report.Issues[0].Kind != IssueInvalid {
+			t.Errorf("Invalid reply should be reported. Got %s", report)
+		}
+	})
+}
+
+func TestReplySets(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	Catcher.Reset().NewMock().WithQuery(`CALL user_report`).WithReplySets(
+		NewReplySet().WithColumns("id", "name").AddRow(1, "First").AddRow(2, "Second"),
+		NewReplySet().WithReply([]map[string]interface{}{{"total": 2}}).
+			WithColumnType("total", ColumnType{DatabaseTypeName: "INTEGER"}),
+	)
+	rows, err := db.Query(`CALL user_report()`)
+	if err != nil {
+		t.Fatalf("Query failed [%v]", err)
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			t.Fatalf("Scan failed [%v]", err)
+		}
+		names = append(names, name)
+	}
+	if strings.Join(names, ",") != "First,Second" {
+		t.Errorf("Rows of the first result set are expected. Got %v", names)
+	}
+
+	if !rows.NextResultSet() {
+		t.Fatalf("Second result set is expected")
+	}
+	columns, _ := rows.Columns()
+	types, _ := rows.ColumnTypes()
+	if len(columns) != 1 || columns[0] != "total" || types[0].DatabaseTypeName() != "INTEGER" {
+		t.Errorf("Columns of the second result set are expected. Got %v", columns)
+	}
+	var total int64
+	if !rows.Next() || rows.Scan(&total) != nil || total != 2 {
+		t.Errorf("Row of the second result set is expected")
+	}
+	if rows.NextResultSet() {
+		t.Errorf("Only two result sets are expected")
+	}
+}
+
+func TestRowError(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+	errBroken := errors.New("connection reset")
+
+	t.Run("Partial result", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT * FROM users`).
+			WithColumns("id").AddRow(1).AddRow(2).AddRow(3).
+			WithRowError(2, errBroken)
+		rows, _ := db.Query(`SELECT * FROM users`)
+		defer rows.Close()
+		read := 0
+		for rows.Next() {
+			read++
+		}
+		if read != 2 || rows.Err() != errBroken {
+			t.Errorf("Two rows and error are expected. Got %d rows and %v", read, rows.Err())
+		}
+	})
+
+	t.Run("Per result set", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`CALL report`).WithReplySets(
+			NewReplySet().WithColumns("id").AddRow(1),
+			NewReplySet().WithColumns("id").AddRow(1).AddRow(2).WithRowError(1, errBroken),
+		)
+		rows, _ := db.Query(`CALL report()`)
+		defer rows.Close()
+		for rows.Next() {
+		}
+		if rows.Err() != nil {
+			t.Errorf("First result set should not fail. Got %v", rows.Err())
+		}
+		if !rows.NextResultSet() {
+			t.Fatalf("Second result set is expected")
+		}
+		read := 0
+		for rows.Next() {
+			read++
+		}
+		if read != 1 || rows.Err() != errBroken {
+			t.Errorf("One row and error are expected. Got %d rows and %v", read, rows.Err())
+		}
+	})
+}
+
+func TestRowSource(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	stopped := make(chan bool, 1)
+	mock := Catcher.Reset().NewMock().WithQuery(`SELECT * FROM events`).WithColumns("id", "name").
+		WithRowSource(func(yield func([]driver.Value) bool) {
+			for i := 1; yield([]driver.Value{i, fmt.Sprintf("event %d", i)}); i++ {
+			}
+			stopped <- true
+		})
+	rows, err := db.Query(`SELECT * FROM events`)
+	if err != nil {
+		t.Fatalf("Query failed [%v]", err)
+	}
+	sum := int64(0)
+	for i := 0; i < 3 && rows.Next(); i++ {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			t.Fatalf("Scan failed [%v]", err)
+		}
+		sum += id
+	}
+	rows.Close()
+
+	if sum != 6 {
+		t.Errorf("First three rows are expected. Got sum %d", sum)
+	}
+	if mock.RowsConsumed != 3 {
+		t.Errorf("Three consumed rows are expected. Got %d", mock.RowsConsumed)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Source should be stopped when rows are closed")
+	}
+}
+
+func TestReplyFunc(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	t.Run("Reply computed from args", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT * FROM users WHERE id`).
+			WithReplyFunc(func(ctx context.Context, query string, args []driver.NamedValue) (*ReplySet, error) {
+				if query != `SELECT * FROM users WHERE id = ?` {
+					return nil, fmt.Errorf("query with placeholders is expected, got %s", query)
+				}
+				if args[0].Value == int64(0) {
+					return nil, sql.ErrNoRows
+				}
+				return NewReplySet().WithColumns("id", "name").AddRow(args[0].Value, fmt.Sprintf("user %v", args[0].Value)), nil
+			})
+		for _, id := range []int64{1, 7} {
+			var name string
+			if err := db.QueryRow(`SELECT * FROM users WHERE id = ?`, id).Scan(new(int64), &name); err != nil {
+				t.Fatalf("Query failed [%v]", err)
+			}
+			if name != fmt.Sprintf("user %d", id) {
+				t.Errorf("Reply should echo id %d. Got %s", id, name)
+			}
+		}
+		if _, err := db.Query(`SELECT * FROM users WHERE id = ?`, 0); err != sql.ErrNoRows {
+			t.Errorf("Error of reply func is expected. Got %v", err)
+		}
+	})
+
+	t.Run("Exec result computed from args", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`INSERT INTO users`).
+			WithExecFunc(func(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+				if query != `INSERT INTO users (id, name) VALUES (?, ?)` {
+					return nil, fmt.Errorf("query with placeholders is expected, got %s", query)
+				}
+				return NewFakeResult(args[0].Value.(int64)*10, int64(len(args))), nil
+			})
+		res, err := db.Exec(`INSERT INTO users (id, name) VALUES (?, ?)`, 4, "Fourth")
+		if err != nil {
+			t.Fatalf("Exec failed [%v]", err)
+		}
+		if id, _ := res.LastInsertId(); id != 40 {
+			t.Errorf("Insert ID computed from args is expected. Got %d", id)
+		}
+		if affected, _ := res.RowsAffected(); affected != 2 {
+			t.Errorf("Affected rows computed from args are expected. Got %d", affected)
+		}
+	})
+}
+
+func TestTemplatedReply(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	Catcher.Reset().SetTemplateSeed(42)
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	Catcher.SetTemplateClock(func() time.Time { return now })
+	Catcher.NewMock().WithQuery(`SELECT * FROM users WHERE email`).Templated().
+		WithColumns("id", "email", "token", "created_at", "limit", "title").
+		AddRow(`{{seq "users"}}`, `{{named "email"}}`, `{{uuid}}`, `{{now}}`, `{{match "LIMIT (\\d+)" 1}}`, `user {{arg 0}}`)
+
+	query := func() (id int64, email, token string, createdAt time.Time, limit, title string) {
+		err := db.QueryRow(`SELECT * FROM users WHERE email = ? LIMIT 5`, "first@example.com").
+			Scan(&id, &email, &token, &createdAt, &limit, &title)
+		if err != nil {
+			t.Fatalf("Query failed [%v]", err)
+		}
+		return
+	}
+	id, email, token, createdAt, limit, title := query()
+	if id != 1 || email != "first@example.com" || !createdAt.Equal(now) || limit != "5" || title != "user first@example.com" {
+		t.Errorf("Unexpected rendered values: %d, %s, %s, %s, %s", id, email, createdAt, limit, title)
+	}
+	if len(token) != 36 {
+		t.Errorf("UUID is expected. Got %s", token)
+	}
+	if id, _, _, _, _, _ := query(); id != 2 {
+		t.Errorf("Sequence should be evaluated on every trigger. Got %d", id)
+	}
+
+	Catcher.SetTemplateSeed(42)
+	if _, _, again, _, _, _ := query(); again != token {
+		t.Errorf("Seeded UUID should be reproducible. Got %s and %s", token, again)
+	}
+}
+
+func TestReturning(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	t.Run("Insert", func(t *testing.T) {
+		created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		Catcher.Reset().NewMock().WithQuery(`INSERT INTO "users"`).WithID(42).
+			WithReply([]map[string]interface{}{{"created_at": created}})
+		var id int64
+		var name string
+		var createdAt time.Time
+		err := db.QueryRow(`INSERT INTO "users" ("name", "age") VALUES ($1, $2) RETURNING "id", "name", "created_at"`, "First", 30).
+			Scan(&id, &name, &createdAt)
+		if err != nil {
+			t.Fatalf("Query failed [%v]", err)
+		}
+		if id != 42 || name != "First" || !createdAt.Equal(created) {
+			t.Errorf("Inserted row is expected. Got %d, %s, %s", id, name, createdAt)
+		}
+	})
+
+	t.Run("Insert several rows", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`INSERT INTO users`).WithIDSequence(10)
+		rows, err := db.Query(`INSERT INTO users (name) VALUES (?), (?) RETURNING id AS user_id, name`, "First", "Second")
+		if err != nil {
+			t.Fatalf("Query failed [%v]", err)
+		}
+		defer rows.Close()
+		columns, _ := rows.Columns()
+		if strings.Join(columns, ",") != "user_id,name" {
+			t.Errorf("Aliased columns are expected. Got %v", columns)
+		}
+		got := []string{}
+		for rows.Next() {
+			var id int64
+			var name string
+			rows.Scan(&id, &name)
+			got = append(got, fmt.Sprintf("%d:%s", id, name))
+		}
+		if strings.Join(got, ",") != "10:First,11:Second" {
+			t.Errorf("Row per inserted tuple is expected. Got %v", got)
+		}
+	})
+
+	t.Run("Without mock", func(t *testing.T) {
+		Catcher.Reset()
+		var id int64
+		if err := db.QueryRow(`INSERT INTO "users" ("name") VALUES ($1) RETURNING "id"`, "First").Scan(&id); err != sql.ErrNoRows {
+			t.Errorf("No rows are expected when nothing matches. Got %v, id %d", err, id)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`UPDATE users`)
+		var id int64
+		var name string
+		if err := db.QueryRow(`UPDATE users SET name = ? WHERE id = ? RETURNING *`, "Renamed", 5).Scan(&id, &name); err != nil {
+			t.Fatalf("Query failed [%v]", err)
+		}
+		if id != 5 || name != "Renamed" {
+			t.Errorf("Updated row is expected. Got %d, %s", id, name)
+		}
+	})
+}
+
+func TestSelectList(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	t.Run("Columns ordered by select list", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`FROM users u WHERE id`).WithReply([]map[string]interface{}{{"age": 30, "name": "First"}})
+		var name string
+		var age int64
+		if err := db.QueryRow(`SELECT u.name, age FROM users u WHERE id = ?`, 1).Scan(&name, &age); err != nil {
+			t.Fatalf("Query failed [%v]", err)
+		}
+		if name != "First" || age != 30 {
+			t.Errorf("Columns should go in order of select list. Got %s, %d", name, age)
+		}
+	})
+
+	t.Run("Select list parsing", func(t *testing.T) {
+		for query, expected := range map[string]string{
+			`SELECT DISTINCT u.id, "name", COUNT(*) AS total, MAX(age) oldest FROM users u`: "id,name,total,oldest",
+			`SELECT id, name FROM users WHERE id IN (SELECT user_id FROM orders)`:           "id,name",
+			`SELECT CASE WHEN a THEN b END AS kind, "end" FROM users`:                       "kind,end",
+			`SELECT * FROM users`:                  "",
+			`SELECT id, age + 1 FROM users`:        "",
+			`SELECT u.* FROM users u`:              "",
+			`SELECT user_id AS userID FROM orders`: "userID",
+			`SELECT CASE WHEN a THEN b END FROM t`: "",
+			`SELECT a AND b FROM users`:            "",
+		} {
+			columns, _ := selectColumns(query)
+			if strings.Join(columns, ",") != expected {
+				t.Errorf("Unexpected columns of %s. Got %v", query, columns)
+			}
+		}
+	})
+
+	t.Run("Mismatched keys", func(t *testing.T) {
+		notSelected, missing := columnMismatch([]string{"user_id", "userID", "Name"}, []string{"name", "user_id", "age"})
+		if strings.Join(notSelected, ",") != "userID" || strings.Join(missing, ",") != "age" {
+			t.Errorf("Unexpected mismatch: not selected %v, missing %v", notSelected, missing)
+		}
+	})
+}
+
+func TestDataset(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+
+	Catcher.Reset().Dataset(`FROM "users"`, []map[string]interface{}{
+		{"id": 1, "name": "First", "team": "a", "deleted_at": nil},
+		{"id": 2, "name": "Second", "team": "b", "deleted_at": nil},
+		{"id": 3, "name": "Third", "team": "a", "deleted_at": time.Now()},
+	})
+	names := func(query string, args ...interface{}) string {
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			t.Fatalf("Query failed [%v]", err)
+		}
+		defer rows.Close()
+		var result []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				t.Fatalf("Scan failed [%v]", err)
+			}
+			result = append(result, name)
+		}
+		return strings.Join(result, ",")
+	}
+
+	for _, tc := range []struct {
+		query    string
+		arg      interface{}
+		expected string
+	}{
+		{`SELECT "name" FROM "users" WHERE "users"."id" = $1`, 2, "Second"},
+		{`SELECT "name" FROM "users" WHERE ("team" = $1) AND "deleted_at" IS NULL ORDER BY "id"`, "a", "First"},
+		{`SELECT "name" FROM "users" WHERE "team" = $1 AND "deleted_at" IS NOT NULL LIMIT 1`, "a", "Third"},
+		{`SELECT "name" FROM "users" WHERE "id" > $1`, 2, "First,Second,Third"},
+		{`SELECT "name" AS n FROM "users" WHERE "id" = $1`, 2, "Second"},
+		{`SELECT u.name title FROM "users" u WHERE u.team = $1 ORDER BY u.id`, "b", "Second"},
+	} {
+		if got := names(tc.query, tc.arg); got != tc.expected {
+			t.Errorf("Unexpected rows of %s. Got %s", tc.query, got)
+		}
+	}
+
+	rows := []map[string]interface{}{{"id": 1, "name": "First"}}
+	for _, query := range []string{`SELECT id, email FROM users`, `SELECT id, UPPER(name) AS name FROM users`} {
+		items, _ := selectList(query)
+		if projected := projectDataset(rows, items); !reflect.DeepEqual(projected, rows) {
+			t.Errorf("Rows should not be projected by %s. Got %v", query, projected)
+		}
+	}
+}
+
+func TestMatchingWithDialect(t *testing.T) {
+	Catcher.Register()
+	db, _ := sql.Open(DriverName, "connection_string")
+	defer Catcher.SetDialect(DialectDefault)
+	Catcher.SetDialect(DialectPostgres)
+
+	t.Run("Pattern with $1 placeholder", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`SELECT * FROM "users" WHERE "id" = $1`).WithReply([]map[string]interface{}{{"name": "First"}})
+		var name string
+		if err := db.QueryRow(`SELECT * FROM "users" WHERE "id" = $1`, 1).Scan(&name); err != nil {
+			t.Fatalf("Pattern with placeholder should match [%v]", err)
+		}
+		if _, times := Catcher.FindReceivedQuery(`SELECT * FROM "users" WHERE "id" = 1`); times != 1 {
+			t.Errorf("Received query should have args rendered by dialect")
+		}
+	})
+
+	t.Run("Pattern with unquoted string arg", func(t *testing.T) {
+		Catcher.Reset().NewMock().WithQuery(`WHERE name = foo`).WithReply([]map[string]interface{}{{"name": "foo"}})
+		var name string
+		if err := db.QueryRow(`SELECT name FROM users WHERE name = ?`, "foo").Scan(&name); err != nil {
+			t.Fatalf("Pattern with unquoted arg should match [%v]", err)
+		}
+		if _, times := Catcher.FindReceivedQuery(`SELECT name FROM users WHERE name = 'foo'`); times != 1 {
+			t.Errorf("Received query should have quoted string")
+		}
+	})
 }
 
 func TestReadOnlyDB(t *testing.T) {
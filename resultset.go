@@ -0,0 +1,116 @@
+package gomocket
+
+// ReplySet is one result set of reply with its own columns and types, used for queries
+// returning several result sets like stored procedures
+type ReplySet struct {
+	Response      []map[string]interface{} // Array of rows to be parsed as result
+	Columns       []string                 // Columns of tabular reply, take precedence over Response
+	Rows          [][]interface{}          // Rows of tabular reply, values go in order of Columns
+	ColumnTypes   map[string]ColumnType    // Declared types of reply columns, inferred from values if missing
+	RowError      error                    // Error returned by rows.Next() after RowErrorAfter rows
+	RowErrorAfter int                      // How many rows are returned before RowError
+	RowSource     RowSource                // Generator of rows pulled lazily, takes precedence over Rows
+}
+
+// NewReplySet creates empty result set for chains of attachments
+// example: NewReplySet().WithColumns("id", "name").AddRow(1, "first")
+func NewReplySet() *ReplySet {
+	return &ReplySet{Response: make([]map[string]interface{}, 0)}
+}
+
+// WithReply sets rows of result set
+func (rs *ReplySet) WithReply(response []map[string]interface{}) *ReplySet {
+	rs.Response = response
+	return rs
+}
+
+// WithColumns starts tabular result set with fixed order of columns
+func (rs *ReplySet) WithColumns(columns ...string) *ReplySet {
+	rs.Columns = columns
+	rs.Rows = make([][]interface{}, 0)
+	return rs
+}
+
+// AddRow adds row to tabular result set, values go in order of columns
+func (rs *ReplySet) AddRow(values ...interface{}) *ReplySet {
+	rs.Rows = append(rs.Rows, append([]interface{}(nil), values...))
+	return rs
+}
+
+// WithColumnType declares type of result set column
+func (rs *ReplySet) WithColumnType(column string, ct ColumnType) *ReplySet {
+	if rs.ColumnTypes == nil {
+		rs.ColumnTypes = make(map[string]ColumnType)
+	}
+	rs.ColumnTypes[column] = ct
+	return rs
+}
+
+// WithRowError makes rows.Next() fail with err after afterRow rows of the result set are read
+func (rs *ReplySet) WithRowError(afterRow int, err error) *ReplySet {
+	rs.RowErrorAfter = afterRow
+	rs.RowError = err
+	return rs
+}
+
+// clone returns deep copy of result set
+func (rs *ReplySet) clone() *ReplySet {
+	clone := &ReplySet{
+		Response:      cloneRows(rs.Response),
+		Columns:       append([]string(nil), rs.Columns...),
+		RowError:      rs.RowError,
+		RowErrorAfter: rs.RowErrorAfter,
+		RowSource:     rs.RowSource,
+	}
+	if rs.Rows != nil {
+		clone.Rows = make([][]interface{}, len(rs.Rows))
+		for i, values := range rs.Rows {
+			clone.Rows[i] = cloneValues(values)
+		}
+	}
+	if rs.ColumnTypes != nil {
+		clone.ColumnTypes = make(map[string]ColumnType, len(rs.ColumnTypes))
+		for col, ct := range rs.ColumnTypes {
+			clone.ColumnTypes[col] = ct
+		}
+	}
+	return clone
+}
+
+// WithReplySets sets several result sets to be returned by the query, available via rows.NextResultSet().
+// Result sets take precedence over Response and tabular reply.
+// example: WithReplySets(NewReplySet().WithReply(users), NewReplySet().WithColumns("total").AddRow(2))
+func (fr *FakeResponse) WithReplySets(sets ...*ReplySet) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.ReplySets = sets
+	fr.convertReplies()
+	return fr
+}
+
+// replySets returns result sets of picked reply, main reply gives the only one if no sets are declared
+func (res callResult) replySets() []*ReplySet {
+	if res.sets != nil {
+		return res.sets
+	}
+	return []*ReplySet{{
+		Response:      res.response,
+		Columns:       res.columns,
+		Rows:          res.rows,
+		ColumnTypes:   res.columnTypes,
+		RowError:      res.rowErr,
+		RowErrorAfter: res.rowErrAfter,
+		RowSource:     res.source,
+	}}
+}
+
+// WithRowError makes rows.Next() fail with err after afterRow rows are read, so handling of rows.Err()
+// and partial results could be tested. Use ReplySet.WithRowError for several result sets.
+// example: WithRowError(2, driver.ErrBadConn)
+func (fr *FakeResponse) WithRowError(afterRow int, err error) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.RowErrorAfter = afterRow
+	fr.RowError = err
+	return fr
+}
@@ -0,0 +1,132 @@
+package gomocket
+
+import (
+	"database/sql/driver"
+	"math/rand"
+	"strings"
+)
+
+// returningColumn is a column of RETURNING clause
+type returningColumn struct {
+	name   string // Name of column in result set, alias if given
+	source string // Column of the table, * for all columns
+}
+
+// returningColumns parses RETURNING clause of statement, false if there is no such clause
+func returningColumns(words []sqlWord) ([]returningColumn, bool) {
+	start, depth := -1, 0
+	for i, w := range words {
+		switch w.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "RETURNING":
+			if depth == 0 && w.ident != "" {
+				start = i + 1
+			}
+		}
+	}
+	if start < 0 {
+		return nil, false
+	}
+
+	var columns []returningColumn
+	var current *returningColumn
+	for i := start; i < len(words); i++ {
+		w := words[i]
+		switch {
+		case w.text == "," || w.text == ";":
+			current = nil
+		case w.text == "*" && current == nil:
+			columns = append(columns, returningColumn{name: "*", source: "*"})
+			current = &columns[len(columns)-1]
+		case w.text == "AS":
+		case w.ident != "" && current == nil:
+			columns = append(columns, returningColumn{name: w.ident, source: w.ident})
+			current = &columns[len(columns)-1]
+		case w.ident != "" && current.source != "*":
+			current.name = w.ident // Alias
+		}
+	}
+	return columns, true
+}
+
+// returningReply emulates reply of INSERT, UPDATE or DELETE with RETURNING clause. Rows are built from
+// columns bound to args, the only record of mock reply and generated insert ID for id column.
+// Nil is returned if there is no such clause or mock has tabular, streamed, computed or several rows reply.
+func returningReply(command, statement string, args []driver.NamedValue, result callResult) *ReplySet {
+	if command != "INSERT" && command != "UPDATE" && command != "DELETE" {
+		return nil
+	}
+	if result.columns != nil || result.sets != nil || result.source != nil || result.replyFunc != nil || len(result.response) > 1 {
+		return nil
+	}
+	words := sqlWords(statement)
+	columns, ok := returningColumns(words)
+	if !ok {
+		return nil
+	}
+
+	records := argBindings(statement, args)
+	if command != "INSERT" && len(records) == 0 {
+		records = []map[string]interface{}{{}}
+	}
+	var defaults map[string]interface{}
+	if len(result.response) > 0 {
+		defaults = result.response[0]
+	}
+	id := result.lastInsertID
+	if id == 0 && command == "INSERT" {
+		id = rand.Int63()
+	}
+
+	reply := NewReplySet().WithColumns(returningNames(columns, records, defaults)...)
+	for i, record := range records {
+		values := make(map[string]interface{}, len(record)+1)
+		if id != 0 {
+			values["id"] = id + int64(i)
+		}
+		for col, v := range defaults {
+			values[strings.ToLower(col)] = v
+		}
+		for col, v := range record {
+			values[strings.ToLower(col)] = v
+		}
+		row := make([]interface{}, 0, len(reply.Columns))
+		for _, col := range columns {
+			if col.source == "*" {
+				for _, name := range allColumns(records, defaults) {
+					row = append(row, values[strings.ToLower(name)])
+				}
+				continue
+			}
+			row = append(row, values[strings.ToLower(col.source)])
+		}
+		reply.AddRow(row...)
+	}
+	return reply
+}
+
+// returningNames returns names of result set columns, * is expanded to all known columns
+func returningNames(columns []returningColumn, records []map[string]interface{}, defaults map[string]interface{}) []string {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col.source == "*" {
+			names = append(names, allColumns(records, defaults)...)
+			continue
+		}
+		names = append(names, col.name)
+	}
+	return names
+}
+
+// allColumns lists id, columns bound to args and columns of mock reply, used for RETURNING *
+func allColumns(records []map[string]interface{}, defaults map[string]interface{}) []string {
+	response := []map[string]interface{}{{"id": nil}}
+	response = append(response, records...)
+	if defaults != nil {
+		response = append(response, defaults)
+	}
+	return mapColumns(response)
+}
@@ -6,13 +6,14 @@ import (
 	"errors"
 	"io"
 	"reflect"
+	"strings"
 	"time"
 )
 
 // RowsCursor is implementation of Rows sql interface
 type RowsCursor struct {
-	cols    []string
-	colType [][]string
+	cols    [][]string
+	colType [][]ColumnType
 	posSet  int
 	posRow  int
 	rows    [][]*row
@@ -22,6 +23,13 @@ type RowsCursor struct {
 	errPos int
 	err    error
 
+	// setErrPos and setErr hold errPos and err of every result set
+	setErrPos []int
+	setErr    []error
+
+	// streams pull rows lazily for streamed result sets, nil for the rest
+	streams []*rowStream
+
 	bytesClone map[*byte][]byte
 }
 
@@ -32,6 +40,11 @@ type row struct {
 // Close closes the rows iterator.
 func (rc *RowsCursor) Close() error {
 	if !rc.closed {
+		for _, stream := range rc.streams {
+			if stream != nil {
+				stream.close()
+			}
+		}
 		for _, bs := range rc.bytesClone {
 			bs[0] = 255 // first byte corrupted
 		}
@@ -42,13 +55,38 @@ func (rc *RowsCursor) Close() error {
 
 // Columns returns the names of the columns.
 func (rc *RowsCursor) Columns() []string {
-	return rc.cols
+	return rc.cols[rc.posSet]
 }
 
 // ColumnTypeScanType may be implemented by Rows. It should return
 // the value type that can be used to scan types into.
 func (rc *RowsCursor) ColumnTypeScanType(index int) reflect.Type {
-	return colTypeToReflectType(rc.colType[rc.posSet][index])
+	return rc.colType[rc.posSet][index].ScanType
+}
+
+// ColumnTypeDatabaseTypeName returns the database system type name
+// without the length, like VARCHAR or BIGINT.
+func (rc *RowsCursor) ColumnTypeDatabaseTypeName(index int) string {
+	return rc.colType[rc.posSet][index].DatabaseTypeName
+}
+
+// ColumnTypeNullable reports if the column may be null, unknown nullability is reported as nullable.
+func (rc *RowsCursor) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return rc.colType[rc.posSet][index].Nullable != NotNull, true
+}
+
+// ColumnTypeLength returns the length of variable length column types,
+// ok is false if length is not declared.
+func (rc *RowsCursor) ColumnTypeLength(index int) (length int64, ok bool) {
+	ct := rc.colType[rc.posSet][index]
+	return ct.Length, ct.Length > 0
+}
+
+// ColumnTypePrecisionScale returns the precision and scale of decimal types,
+// ok is false if precision is not declared.
+func (rc *RowsCursor) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	ct := rc.colType[rc.posSet][index]
+	return ct.Precision, ct.Scale, ct.Precision > 0
 }
 
 // Next is called to populate the next row of data into
@@ -61,6 +99,17 @@ func (rc *RowsCursor) Next(accumulator []driver.Value) error {
 	if rc.posRow == rc.errPos {
 		return rc.err
 	}
+	if stream := rc.streams[rc.posSet]; stream != nil {
+		values, ok, err := stream.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return io.EOF // per interface spec
+		}
+		copy(accumulator, values)
+		return nil
+	}
 	if rc.posRow >= len(rc.rows[rc.posSet]) {
 		return io.EOF // per interface spec
 	}
@@ -92,8 +141,12 @@ func (rc *RowsCursor) HasNextResultSet() bool {
 // if there are remaining rows in the current result set.
 func (rc *RowsCursor) NextResultSet() error {
 	if rc.HasNextResultSet() {
+		if stream := rc.streams[rc.posSet]; stream != nil {
+			stream.close()
+		}
 		rc.posSet++
 		rc.posRow = -1
+		rc.errPos, rc.err = rc.setErrPos[rc.posSet], rc.setErr[rc.posSet]
 		return nil
 	}
 	return io.EOF // Per interface spec.
@@ -122,5 +175,24 @@ func colTypeToReflectType(typ string) reflect.Type {
 	case "datetime":
 		return reflect.TypeOf(time.Time{})
 	}
-	panic("invalid fakedb column type of " + typ)
+	// Common SQL type names, size like VARCHAR(255) is ignored
+	name := strings.ToUpper(typ)
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+	switch strings.TrimSpace(name) {
+	case "BOOL", "BOOLEAN", "BIT":
+		return reflect.TypeOf(false)
+	case "INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT", "MEDIUMINT", "SERIAL", "BIGSERIAL", "INT2", "INT4", "INT8":
+		return reflect.TypeOf(int64(0))
+	case "FLOAT", "DOUBLE", "REAL", "DECIMAL", "NUMERIC", "FLOAT4", "FLOAT8", "DOUBLE PRECISION":
+		return reflect.TypeOf(float64(0))
+	case "CHAR", "VARCHAR", "TEXT", "NCHAR", "NVARCHAR", "UUID", "JSON", "JSONB":
+		return reflect.TypeOf("")
+	case "BLOB", "BYTEA", "BINARY", "VARBINARY":
+		return reflect.TypeOf([]byte(nil))
+	case "DATE", "TIME", "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
+		return reflect.TypeOf(time.Time{})
+	}
+	return nil
 }
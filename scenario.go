@@ -0,0 +1,76 @@
+package gomocket
+
+// ScenarioStarted is the state every scenario has before any mock moved it
+const ScenarioStarted = "Started"
+
+// ScenarioState returns current state of the scenario
+func (mc *MockCatcher) ScenarioState(name string) string {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.scenarioState(name)
+}
+
+// SetScenarioState moves scenario to the state, e.g. to start test from the middle of workflow
+func (mc *MockCatcher) SetScenarioState(name, state string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.scenarios == nil {
+		mc.scenarios = make(map[string]string)
+	}
+	mc.scenarios[name] = state
+}
+
+func (mc *MockCatcher) scenarioState(name string) string {
+	if state, ok := mc.scenarios[name]; ok {
+		return state
+	}
+	return ScenarioStarted
+}
+
+// isScenarioMatch returns true if mock is not a part of scenario or scenario is in required state
+func (mc *MockCatcher) isScenarioMatch(fr *FakeResponse) bool {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	if fr.Scenario == "" || fr.RequiredScenarioState == "" {
+		return true
+	}
+	return mc.scenarioState(fr.Scenario) == fr.RequiredScenarioState
+}
+
+// moveScenario switches scenario of triggered mock to the new state if any
+func (mc *MockCatcher) moveScenario(fr *FakeResponse) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	if fr.Scenario == "" || fr.NewScenarioState == "" {
+		return
+	}
+	if mc.scenarios == nil {
+		mc.scenarios = make(map[string]string)
+	}
+	mc.scenarios[fr.Scenario] = fr.NewScenarioState
+}
+
+// InScenario makes mock a part of named scenario
+// example: InScenario("orders").WhenScenarioStateIs("paid").WillSetStateTo("shipped")
+func (fr *FakeResponse) InScenario(name string) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.Scenario = name
+	return fr
+}
+
+// WhenScenarioStateIs sets mock to be matched only when its scenario is in the state
+func (fr *FakeResponse) WhenScenarioStateIs(state string) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.RequiredScenarioState = state
+	return fr
+}
+
+// WillSetStateTo moves scenario to the state when mock is triggered
+func (fr *FakeResponse) WillSetStateTo(state string) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.NewScenarioState = state
+	return fr
+}
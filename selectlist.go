@@ -0,0 +1,166 @@
+package gomocket
+
+import (
+	"log"
+	"strings"
+)
+
+// selectEnd lists keywords finishing SELECT list
+var selectEnd = map[string]bool{
+	"FROM": true, "WHERE": true, "GROUP": true, "ORDER": true, "LIMIT": true, "UNION": true, "INTO": true, ";": true,
+}
+
+// expressionKeywords lists keywords of expressions which are never column names or aliases
+var expressionKeywords = map[string]bool{
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true, "AND": true, "OR": true, "NOT": true,
+	"IS": true, "NULL": true, "IN": true, "LIKE": true, "BETWEEN": true, "EXISTS": true, "TRUE": true, "FALSE": true,
+}
+
+// selectItem is an item of SELECT list
+type selectItem struct {
+	name   string // Name of column in result set, alias if given
+	source string // Selected column, empty for expressions
+}
+
+// selectColumns returns names of columns listed by SELECT statement, false when the list could not be
+// understood, e.g. it has * or expressions without aliases
+func selectColumns(statement string) ([]string, bool) {
+	items, ok := selectList(statement)
+	if !ok {
+		return nil, false
+	}
+	columns := make([]string, len(items))
+	for i, item := range items {
+		columns[i] = item.name
+	}
+	return columns, true
+}
+
+// selectList returns items of SELECT list, false when the list could not be understood
+func selectList(statement string) ([]selectItem, bool) {
+	words := sqlWords(statement)
+	if len(words) < 2 || words[0].text != "SELECT" {
+		return nil, false
+	}
+	i := 1
+	if words[i].text == "DISTINCT" || words[i].text == "ALL" {
+		i++
+	}
+
+	var items []selectItem
+	var item []sqlWord
+	depth := 0
+	for ; i <= len(words); i++ {
+		if i < len(words) {
+			w := words[i]
+			if w.text == "(" {
+				depth++
+			} else if w.text == ")" {
+				depth--
+			}
+			if depth > 0 || (w.text != "," && !selectEnd[w.text]) {
+				item = append(item, w)
+				continue
+			}
+		}
+		name, ok := selectItemName(item)
+		if !ok {
+			return nil, false
+		}
+		items = append(items, selectItem{name: name, source: selectItemSource(item)})
+		item = nil
+		if i == len(words) || words[i].text != "," {
+			break
+		}
+	}
+	return items, true
+}
+
+// selectItemName returns name of column given by item of SELECT list, false for expressions without alias
+func selectItemName(item []sqlWord) (string, bool) {
+	n := len(item)
+	if n == 0 || !isName(item[n-1]) {
+		return "", false
+	}
+	if n == 1 {
+		return item[0].ident, true
+	}
+	if prev := item[n-2]; prev.text == "AS" || prev.text == ")" || prev.text == "END" || isName(prev) {
+		return item[n-1].ident, true
+	}
+	return "", false
+}
+
+// selectItemSource returns column selected by item like column, column alias or column AS alias,
+// empty for expressions
+func selectItemSource(item []sqlWord) string {
+	switch n := len(item); {
+	case n >= 1 && n <= 2 && isName(item[0]) && isName(item[n-1]):
+		return item[0].ident
+	case n == 3 && isName(item[0]) && item[1].text == "AS":
+		return item[0].ident
+	}
+	return ""
+}
+
+// isName returns true if word is an identifier, but not a keyword of expression like END of CASE
+func isName(w sqlWord) bool {
+	return w.ident != "" && (w.quoted || !expressionKeywords[w.text])
+}
+
+// orderBySelect puts reply columns in order of SELECT list, columns which are not selected go last
+func orderBySelect(columns, selected []string) []string {
+	ordered := make([]string, 0, len(columns))
+	used := make([]bool, len(columns))
+	for _, name := range selected {
+		for i, col := range columns {
+			if !used[i] && strings.EqualFold(col, name) {
+				used[i] = true
+				ordered = append(ordered, col)
+				break
+			}
+		}
+	}
+	for i, col := range columns {
+		if !used[i] {
+			ordered = append(ordered, col)
+		}
+	}
+	return ordered
+}
+
+// columnMismatch lists reply columns which are not selected and selected columns missing in reply
+func columnMismatch(columns, selected []string) (notSelected, missing []string) {
+	contains := func(list []string, name string) bool {
+		for _, s := range list {
+			if strings.EqualFold(s, name) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, col := range columns {
+		if !contains(selected, col) {
+			notSelected = append(notSelected, col)
+		}
+	}
+	for _, name := range selected {
+		if !contains(columns, name) {
+			missing = append(missing, name)
+		}
+	}
+	return notSelected, missing
+}
+
+// warnColumnMismatch logs reply columns which do not match SELECT list of the query
+func warnColumnMismatch(query string, fr *FakeResponse, columns, selected []string) {
+	if len(columns) == 0 {
+		return // Empty reply has no columns at all
+	}
+	notSelected, missing := columnMismatch(columns, selected)
+	if len(notSelected) == 0 && len(missing) == 0 {
+		return
+	}
+	log.Printf("mock_catcher: [COLUMN MISMATCH]: %s replied by mock %s, not selected columns: %v, missing columns: %v",
+		query, describeMock(fr), notSelected, missing)
+}
@@ -0,0 +1,161 @@
+package gomocket
+
+import (
+	"errors"
+)
+
+// SequencePolicy defines what sequential values return after the last one is used
+type SequencePolicy int
+
+const (
+	// RepeatLast keeps returning the last value of the sequence
+	RepeatLast SequencePolicy = iota
+	// Cycle starts the sequence from the beginning
+	Cycle
+	// FailWhenExhausted returns ErrSequenceExhausted for every next trigger
+	FailWhenExhausted
+)
+
+// ErrSequenceExhausted is returned when all sequential values are used and policy is FailWhenExhausted
+var ErrSequenceExhausted = errors.New("mock_catcher: sequential replies are exhausted")
+
+// index returns position in the sequence of given length for the trigger number (starting from 1)
+func (p SequencePolicy) index(trigger uint32, length int) (int, bool) {
+	pos := 0
+	if trigger > 0 {
+		pos = int(trigger - 1)
+	}
+	if pos < length {
+		return pos, true
+	}
+	switch p {
+	case Cycle:
+		return pos % length, true
+	case FailWhenExhausted:
+		return 0, false
+	}
+	return length - 1, true
+}
+
+// callResult holds values picked by a mock for one particular trigger
+type callResult struct {
+	response     []map[string]interface{}
+	columns      []string        // Columns of tabular reply, nil for map reply
+	rows         [][]interface{} // Rows of tabular reply
+	columnTypes  map[string]ColumnType
+	sets         []*ReplySet // Several result sets, take precedence over the rest of reply
+	rowErr       error
+	rowErrAfter  int
+	source       RowSource
+	replyFunc    ReplyFunc
+	execFunc     ExecFunc
+	templated    bool
+	dataset      []map[string]interface{}
+	rowsAffected int64
+	lastInsertID int64
+	err          error
+}
+
+// resultFor picks response, affected rows, insert ID and error for the trigger number
+func (fr *FakeResponse) resultFor(trigger uint32) callResult {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	res := callResult{
+		response:     fr.Response,
+		columns:      fr.Columns,
+		rows:         fr.Rows,
+		columnTypes:  fr.ColumnTypes,
+		sets:         fr.ReplySets,
+		rowErr:       fr.RowError,
+		rowErrAfter:  fr.RowErrorAfter,
+		source:       fr.RowSource,
+		replyFunc:    fr.ReplyFunc,
+		execFunc:     fr.ExecFunc,
+		templated:    fr.IsTemplated,
+		dataset:      fr.Dataset,
+		rowsAffected: fr.RowsAffected,
+		lastInsertID: fr.LastInsertID,
+		err:          fr.Error,
+	}
+	exhausted := false
+	if len(fr.Replies) > 0 {
+		if i, ok := fr.ExhaustedPolicy.index(trigger, len(fr.Replies)); ok {
+			res.response = fr.Replies[i]
+			res.columns, res.rows, res.sets, res.source = nil, nil, nil, nil
+		} else {
+			exhausted = true
+		}
+	}
+	if len(fr.RowsAffectedSequence) > 0 {
+		if i, ok := fr.ExhaustedPolicy.index(trigger, len(fr.RowsAffectedSequence)); ok {
+			res.rowsAffected = fr.RowsAffectedSequence[i]
+		} else {
+			exhausted = true
+		}
+	}
+	if len(fr.LastInsertIDSequence) > 0 {
+		if i, ok := fr.ExhaustedPolicy.index(trigger, len(fr.LastInsertIDSequence)); ok {
+			res.lastInsertID = fr.LastInsertIDSequence[i]
+		} else {
+			exhausted = true
+		}
+	}
+	if len(fr.Errors) > 0 {
+		if i, ok := fr.ExhaustedPolicy.index(trigger, len(fr.Errors)); ok {
+			res.err = fr.Errors[i]
+		} else {
+			exhausted = true
+		}
+	}
+	if exhausted {
+		res.err = ErrSequenceExhausted
+	}
+	if fr.replyErr != nil {
+		res.err = fr.replyErr
+	}
+	return res
+}
+
+// WithReplies sets responses to be returned one by one on each trigger
+// example: WithReplies(firstPage, secondPage, []map[string]interface{}{})
+func (fr *FakeResponse) WithReplies(responses ...[]map[string]interface{}) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.Replies = responses
+	fr.convertReplies()
+	return fr
+}
+
+// WithRowsNumSequence sets affected rows counts to be returned one by one on each trigger
+func (fr *FakeResponse) WithRowsNumSequence(nums ...int64) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.RowsAffectedSequence = nums
+	return fr
+}
+
+// WithIDSequence sets insert IDs to be returned one by one on each trigger
+func (fr *FakeResponse) WithIDSequence(ids ...int64) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.LastInsertIDSequence = ids
+	return fr
+}
+
+// WithErrorSequence sets errors to be returned one by one on each trigger, nil means success
+// example: WithErrorSequence(driver.ErrBadConn, nil)
+func (fr *FakeResponse) WithErrorSequence(errs ...error) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.Errors = errs
+	return fr
+}
+
+// WhenExhausted sets what sequential values return after the last one is used, RepeatLast by default
+func (fr *FakeResponse) WhenExhausted(policy SequencePolicy) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.ExhaustedPolicy = policy
+	return fr
+}
@@ -16,8 +16,11 @@ type FakeStmt struct {
 	next         *FakeStmt // used for returning multiple results.
 	closed       bool      // If connection closed already
 	colName      []string  // Names of columns in response
-	colType      []string  // Not used for now
 	placeholders int       // Amount of passed args
+	argOrdinals  []int     // Ordinals of args used by statement of multi-statement query
+	argNames     []string  // Names of :name args used by statement of multi-statement query
+	renumber     bool      // Args of the statement should be renumbered as it uses ? or :name placeholders
+	argOrder     []int     // Ordinals of args in order of the first use, index gives statement-local ordinal
 }
 
 // ColumnConverter returns a ValueConverter for the provided
@@ -65,15 +68,47 @@ func (s *FakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (d
 		panic("writting to read only connection")
 	}
 
-	fResp := Catcher.FindResponse(s.q, args)
+	if s.next == nil {
+		return s.exec(ctx, args)
+	}
+
+	// Multi-statement query, affected rows are summed up and the last insert ID is returned
+	var insertID, rowsAffected int64
+	for st := s; st != nil; st = st.next {
+		res, err := st.exec(ctx, st.statementArgs(args))
+		if err != nil {
+			return nil, err
+		}
+		if id, err := res.LastInsertId(); err == nil && id != 0 {
+			insertID = id
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			rowsAffected += n
+		}
+	}
+	return NewFakeResult(insertID, rowsAffected), nil
+}
+
+// exec executes single statement
+func (s *FakeStmt) exec(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	fResp, trigger := Catcher.findResponse(s.q, s.q, args)
 
 	// To emulate any exception during query which returns rows
 	if fResp.Exceptions != nil && fResp.Exceptions.HookExecBadConnection != nil && fResp.Exceptions.HookExecBadConnection() {
 		return nil, driver.ErrBadConn
 	}
 
-	if fResp.Error != nil {
-		return nil, fResp.Error
+	result := fResp.resultFor(trigger)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	if result.execFunc != nil {
+		res, err := result.execFunc(ctx, s.q, args)
+		if err == nil && fResp.Callback != nil {
+			fResp.Callback(s.q, args)
+		}
+		return res, err
 	}
 
 	if fResp.Callback != nil {
@@ -82,16 +117,16 @@ func (s *FakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (d
 
 	switch s.command {
 	case "INSERT":
-		id := fResp.LastInsertID
+		id := result.lastInsertID
 		if id == 0 {
 			id = rand.Int63()
 		}
 		res := NewFakeResult(id, 1)
 		return res, nil
 	case "UPDATE":
-		return driver.RowsAffected(fResp.RowsAffected), nil
+		return driver.RowsAffected(result.rowsAffected), nil
 	case "DELETE":
-		return driver.RowsAffected(fResp.RowsAffected), nil
+		return driver.RowsAffected(result.rowsAffected), nil
 	}
 	return nil, fmt.Errorf("unimplemented statement Exec command type of %q", s.command)
 }
@@ -112,64 +147,136 @@ func (s *FakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (
 		return nil, errClosed
 	}
 
-	s.q = completeStatement(s.q, args)
+	cursor := &RowsCursor{
+		posRow: -1,
+		errPos: -1,
+		closed: false,
+	}
+
+	// Every statement of multi-statement query gives own result set
+	for st := s; st != nil; st = st.next {
+		stArgs := args
+		if s.next != nil {
+			stArgs = st.statementArgs(args)
+		}
+		sets, err := st.query(ctx, stArgs)
+		if err != nil {
+			return nil, err
+		}
+		for _, set := range sets {
+			cursor.rows = append(cursor.rows, set.rows)
+			cursor.cols = append(cursor.cols, set.columns)
+			cursor.colType = append(cursor.colType, set.types)
+			cursor.setErrPos = append(cursor.setErrPos, set.errPos)
+			cursor.setErr = append(cursor.setErr, set.err)
+			cursor.streams = append(cursor.streams, set.stream)
+		}
+	}
+	cursor.errPos, cursor.err = cursor.setErrPos[0], cursor.setErr[0]
+
+	return cursor, nil
+}
+
+// resultSet holds columns, their types and rows of one result set
+type resultSet struct {
+	columns []string
+	types   []ColumnType
+	rows    []*row
+	errPos  int        // Position of row which fails with err, -1 means none
+	err     error      // Error of rows.Next()
+	stream  *rowStream // Rows pulled lazily instead of rows, nil if reply is not streamed
+}
+
+// query executes single statement and returns its result sets
+func (s *FakeStmt) query(ctx context.Context, args []driver.NamedValue) ([]resultSet, error) {
+	query := matchingStatement(s.q, args)
 
-	fResp := Catcher.FindResponse(s.q, args)
+	fResp, trigger := Catcher.findResponse(s.q, query, args)
 
 	if fResp.Exceptions != nil && fResp.Exceptions.HookQueryBadConnection != nil && fResp.Exceptions.HookQueryBadConnection() {
 		return nil, driver.ErrBadConn
 	}
 
-	if fResp.Error != nil {
-		return nil, fResp.Error
+	result := fResp.resultFor(trigger)
+	if result.err != nil {
+		return nil, result.err
 	}
 
-	resultRows := make([][]*row, 0, 1)
-	columnNames := make([]string, 0, 1)
-	columnTypes := make([][]string, 0, 1)
-	rows := []*row{}
-
-	// Check if we have such query in the map
-	colIndexes := make(map[string]int)
-
-	// Collecting column names from all records
-	if len(fResp.Response) > 0 {
-		for _, resp := range fResp.Response {
-			for colName := range resp {
-				if _, ok := colIndexes[colName]; ok {
-					continue
-				} else {
-					colIndexes[colName] = len(columnNames)
-					columnNames = append(columnNames, colName)
-				}
+	replies := result.replySets()
+	// RETURNING is emulated only by triggered mocks, so missing ones are not hidden
+	if reply := returningReply(s.command, s.q, args, result); reply != nil && trigger > 0 {
+		replies = []*ReplySet{reply}
+	} else if result.dataset != nil && result.replyFunc == nil {
+		rows := result.dataset
+		if conditions, ok := whereConditions(s.q, args); ok {
+			rows = filterDataset(rows, conditions)
+		}
+		if selected, ok := selectList(s.q); ok {
+			rows = projectDataset(rows, selected)
+		}
+		replies = []*ReplySet{{Response: rows, ColumnTypes: result.columnTypes}}
+	} else if result.replyFunc != nil {
+		reply, err := result.replyFunc(ctx, s.q, args)
+		if err != nil {
+			return nil, err
+		}
+		if reply == nil {
+			reply = NewReplySet()
+		}
+		if reply, err = fResp.convertSet(reply, -1); err != nil {
+			return nil, err
+		}
+		replies = []*ReplySet{reply}
+	}
+	if result.templated && result.replyFunc == nil {
+		rendered := make([]*ReplySet, len(replies))
+		for i, reply := range replies {
+			var err error
+			if rendered[i], err = Catcher.renderSet(reply, s.q, query, args); err != nil {
+				return nil, err
 			}
 		}
+		replies = rendered
 	}
 
-	// Extracting values from result according columns
-	for _, record := range fResp.Response {
-		oneRow := &row{cols: make([]interface{}, len(columnNames))}
-		for _, col := range columnNames {
-			oneRow.cols[colIndexes[col]] = record[col]
-		}
-		rows = append(rows, oneRow)
+	var selected []string
+	if s.command == "SELECT" {
+		selected, _ = selectColumns(s.q)
 	}
-	resultRows = append(resultRows, rows)
 
-	cursor := &RowsCursor{
-		posRow:  -1,
-		rows:    resultRows,
-		cols:    columnNames,
-		colType: columnTypes, // TODO: implement support of that
-		errPos:  -1,
-		closed:  false,
+	var sets []resultSet
+	for _, reply := range replies {
+		columnNames, rows, err := replyRows(reply, selected)
+		if err != nil {
+			return nil, err
+		}
+		if selected != nil && Catcher.WarnOnColumnMismatch {
+			warnColumnMismatch(query, fResp, columnNames, selected)
+		}
+		set := resultSet{
+			columns: columnNames,
+			types:   resolveColumnTypes(columnNames, reply.ColumnTypes, rows),
+			rows:    rows,
+			errPos:  -1,
+		}
+		if reply.RowSource != nil {
+			set.stream = newRowStream(reply.RowSource, columnNames, fResp)
+		}
+		if reply.RowError != nil {
+			set.errPos = reply.RowErrorAfter
+			if set.stream == nil {
+				set.errPos = minInt(reply.RowErrorAfter, len(rows))
+			}
+			set.err = reply.RowError
+		}
+		sets = append(sets, set)
 	}
 
 	if fResp.Callback != nil {
-		fResp.Callback(s.q, args)
+		fResp.Callback(query, args)
 	}
 
-	return cursor, nil
+	return sets, nil
 }
 
 // NumInput returns the number of placeholder parameters.
@@ -188,6 +295,7 @@ var HookBadCommit func() bool
 // Commit commits the transaction
 func (tx *FakeTx) Commit() error {
 	tx.c.currTx = nil
+	Catcher.journalMarker(MarkerCommit)
 	if HookBadCommit != nil && HookBadCommit() {
 		return driver.ErrBadConn
 	}
@@ -200,6 +308,7 @@ var HookBadRollback func() bool
 // Rollback rollbacks the transaction
 func (tx *FakeTx) Rollback() error {
 	tx.c.currTx = nil
+	Catcher.journalMarker(MarkerRollback)
 	if HookBadRollback != nil && HookBadRollback() {
 		return driver.ErrBadConn
 	}
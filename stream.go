@@ -0,0 +1,99 @@
+package gomocket
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// RowSource generates rows of reply on demand, values go in order of declared columns.
+// Generation should stop as soon as yield returns false, that happens when rows are closed.
+type RowSource func(yield func(values []driver.Value) bool)
+
+// WithRowSource sets generator of tabular reply rows, columns are declared by WithColumns.
+// Rows are pulled from the source lazily by rows.Next(), so huge result sets are never kept in memory.
+// Source is called from scratch on every trigger, rows read by caller are counted in RowsConsumed.
+//
+//	WithColumns("id").WithRowSource(func(yield func([]driver.Value) bool) {
+//		for i := int64(1); i <= 1000000 && yield([]driver.Value{i}); i++ {
+//		}
+//	})
+func (fr *FakeResponse) WithRowSource(source RowSource) *FakeResponse {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.RowSource = source
+	return fr
+}
+
+// WithRowSource sets generator of result set rows, columns are declared by WithColumns
+func (rs *ReplySet) WithRowSource(source RowSource) *ReplySet {
+	rs.RowSource = source
+	return rs
+}
+
+// rowStream pulls rows from RowSource running in separate goroutine one by one
+type rowStream struct {
+	source   RowSource
+	columns  []string
+	mock     *FakeResponse // Mock counting consumed rows
+	rows     chan []driver.Value
+	stop     chan struct{}
+	started  bool
+	stopped  bool
+	consumed int
+}
+
+func newRowStream(source RowSource, columns []string, mock *FakeResponse) *rowStream {
+	return &rowStream{source: source, columns: columns, mock: mock}
+}
+
+// next returns next row converted to driver values, false when the source is over
+func (s *rowStream) next() ([]driver.Value, bool, error) {
+	if s.stopped {
+		return nil, false, nil
+	}
+	if !s.started {
+		s.started = true
+		s.rows = make(chan []driver.Value)
+		s.stop = make(chan struct{})
+		go func() {
+			defer close(s.rows)
+			s.source(func(values []driver.Value) bool {
+				select {
+				case s.rows <- values:
+					return true
+				case <-s.stop:
+					return false
+				}
+			})
+		}()
+	}
+	values, ok := <-s.rows
+	if !ok {
+		s.stopped = true
+		return nil, false, nil
+	}
+	if len(values) != len(s.columns) {
+		return nil, false, fmt.Errorf("mock_catcher: row %d has %d values, but %d columns declared", s.consumed, len(values), len(s.columns))
+	}
+	row := make([]driver.Value, len(values))
+	for i, value := range values {
+		v, err := convertValue(value)
+		if err != nil {
+			return nil, false, &ReplyValueError{Mock: s.mock, Reply: -1, Row: s.consumed, Column: s.columns[i], Err: err}
+		}
+		row[i] = v
+	}
+	s.consumed++
+	s.mock.mu.Lock()
+	s.mock.RowsConsumed++
+	s.mock.mu.Unlock()
+	return row, true, nil
+}
+
+// close stops the source if it is still running
+func (s *rowStream) close() {
+	if s.started && !s.stopped {
+		s.stopped = true
+		close(s.stop)
+	}
+}
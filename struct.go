@@ -0,0 +1,198 @@
+package gomocket
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy converts struct field name to column name when field has no db or gorm column tag
+type NamingStrategy func(field string) string
+
+// ColumnNaming is used by WithReplyStructs for fields without tags, snake_case by default the same way GORM does
+var ColumnNaming NamingStrategy = SnakeCase
+
+// SnakeCase converts field name like UserID or HTTPServer to user_id or http_server
+func SnakeCase(field string) string {
+	runes := []rune(field)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// WithReplyStructs builds tabular reply from slice of structs or pointers to structs.
+// Column names are taken from `db:"name"` or `gorm:"column:name"` tags, otherwise ColumnNaming is applied
+// to field name. Embedded structs are flattened, nil pointers give NULL and driver.Valuer fields like
+// sql.NullString give their values. Field types declare column types not declared by WithColumnType.
+// example: WithReplyStructs([]User{{ID: 1, FirstName: "First"}})
+func (fr *FakeResponse) WithReplyStructs(items interface{}) *FakeResponse {
+	list := reflect.ValueOf(items)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		panic(fmt.Sprintf("mock_catcher: WithReplyStructs expects slice of structs, got %T", items))
+	}
+	itemType := list.Type().Elem()
+	for itemType.Kind() == reflect.Ptr {
+		itemType = itemType.Elem()
+	}
+	if itemType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("mock_catcher: WithReplyStructs expects slice of structs, got %T", items))
+	}
+
+	fields := structColumns(itemType, nil, "")
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.column
+	}
+	fr.WithColumns(columns...)
+	fr.mu.Lock()
+	for _, f := range fields {
+		if _, ok := fr.ColumnTypes[f.column]; ok {
+			continue
+		}
+		if fr.ColumnTypes == nil {
+			fr.ColumnTypes = make(map[string]ColumnType)
+		}
+		fr.ColumnTypes[f.column] = fieldColumnType(f.typ)
+	}
+	fr.mu.Unlock()
+	for i := 0; i < list.Len(); i++ {
+		item := list.Index(i)
+		values := make([]interface{}, len(fields))
+		for j, f := range fields {
+			values[j] = fieldValue(item, f.index)
+		}
+		fr.AddRow(values...)
+	}
+	return fr
+}
+
+// structColumn is a column mapped to struct field
+type structColumn struct {
+	column string
+	index  []int        // Index of the field for reflect.Value.FieldByIndex, embedded structs included
+	typ    reflect.Type // Type of the field
+}
+
+// fieldColumnType declares column type by type of struct field, pointers and sql.Null* types are nullable.
+// Database type name of driver.Valuer fields is left to be inferred from values.
+func fieldColumnType(t reflect.Type) ColumnType {
+	ct := ColumnType{}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		ct.Nullable = Nullable
+	}
+	if isValueStruct(t) && t.PkgPath() != "time" {
+		ct.ScanType = t
+		ct.Nullable = Nullable
+		return ct
+	}
+	ct.DatabaseTypeName = databaseTypeOf(reflect.Zero(t).Interface())
+	return ct
+}
+
+// structColumns lists columns of struct fields, embedded structs are flattened
+func structColumns(t reflect.Type, parent []int, prefix string) []structColumn {
+	var columns []structColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int(nil), parent...), i)
+		dbTag := field.Tag.Get("db")
+		gormTag := parseGormTag(field.Tag.Get("gorm"))
+		// gorm:"-:migration" fields are still read and written by GORM, only - and -:all are ignored
+		if ignored := strings.ToLower(gormTag["-"]); dbTag == "-" || ignored == "-" || ignored == "all" {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		isEmbedded := (field.Anonymous && dbTag == "") || gormTag["embedded"] != ""
+		if isEmbedded && fieldType.Kind() == reflect.Struct && !isValueStruct(fieldType) {
+			columns = append(columns, structColumns(fieldType, index, prefix+gormTag["embeddedprefix"])...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := ColumnNaming(field.Name)
+		if gormTag["column"] != "" {
+			name = gormTag["column"]
+		}
+		if dbTag != "" {
+			name = strings.Split(dbTag, ",")[0]
+		}
+		columns = append(columns, structColumn{column: prefix + name, index: index, typ: field.Type})
+	}
+	return columns
+}
+
+// parseGormTag parses `gorm:"column:name;embedded;embeddedPrefix:author_"` into lower cased keys
+func parseGormTag(tag string) map[string]string {
+	settings := make(map[string]string)
+	for _, part := range strings.Split(tag, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, ":", 2)
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		if len(kv) == 2 {
+			settings[key] = strings.TrimSpace(kv[1])
+		} else {
+			settings[key] = key
+		}
+	}
+	return settings
+}
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// isValueStruct returns true for structs which are values by themselves like time.Time or sql.NullString
+func isValueStruct(t reflect.Type) bool {
+	return t.Implements(valuerType) || reflect.PtrTo(t).Implements(valuerType) || t.PkgPath() == "time"
+}
+
+// fieldValue returns value of the field by index, nil if any pointer on the way is nil
+func fieldValue(item reflect.Value, index []int) interface{} {
+	v := item
+	for _, i := range index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Implements(valuerType) {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.Type().Implements(valuerType) && v.CanAddr() && v.Addr().Type().Implements(valuerType) {
+		v = v.Addr()
+	}
+	if v.Type().Implements(valuerType) {
+		value, err := v.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return nil
+		}
+		return value
+	}
+	return v.Interface()
+}
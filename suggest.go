@@ -0,0 +1,147 @@
+package gomocket
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SuggestionsLimit is how many closest patterns are reported for unmatched queries
+var SuggestionsLimit = 3
+
+// Suggestion is a registered pattern similar to unmatched query
+type Suggestion struct {
+	Mock     *FakeResponse // Mock with similar pattern
+	Distance int           // Edit distance between pattern and the closest part of the query
+	Diff     string        // Character level diff, [-removed-] from pattern and {+added+} by query
+}
+
+// suggest returns up to limit mocks which patterns are closest to the normalized query
+func (mc *MockCatcher) suggest(query string, limit int) []Suggestion {
+	suggestions := make([]Suggestion, 0, len(mc.Mocks))
+	for _, resp := range mc.Mocks {
+		resp.mu.RLock()
+		pattern, strict := resp.Pattern, resp.Strict
+		resp.mu.RUnlock()
+		if pattern == "" {
+			continue
+		}
+		// Not strict patterns are compared with the closest part of the query as they are matched by strings.Contains()
+		distance, diff := editDiff(pattern, query, !strict)
+		suggestions = append(suggestions, Suggestion{Mock: resp, Distance: distance, Diff: diff})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Distance < suggestions[j].Distance
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// formatSuggestions renders suggestions to be attached to unmatched query messages
+func formatSuggestions(suggestions []Suggestion) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("mock_catcher: closest patterns:")
+	for _, s := range suggestions {
+		fmt.Fprintf(&b, "\n  distance %d: %s", s.Distance, s.Diff)
+	}
+	return b.String()
+}
+
+// editDiff calculates Levenshtein distance between two strings and marks the differences.
+// With substring set distance is calculated to the closest substring of to.
+func editDiff(from, to string, substring bool) (int, string) {
+	a, b := []rune(from), []rune(to)
+	dist := make([][]int, len(a)+1)
+	for i := range dist {
+		dist[i] = make([]int, len(b)+1)
+		dist[i][0] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		if !substring {
+			dist[0][j] = j
+		}
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			dist[i][j] = minInt(dist[i-1][j]+1, dist[i][j-1]+1, dist[i-1][j-1]+cost)
+		}
+	}
+
+	// Walking back through the matrix to collect edit operations
+	type op struct {
+		kind byte // '=', '-' or '+'
+		r    rune
+	}
+	ops := make([]op, 0, len(a)+len(b))
+	i, j := len(a), len(b)
+	if substring {
+		for k := range b {
+			if dist[i][k] < dist[i][j] {
+				j = k
+			}
+		}
+	}
+	distance := dist[i][j]
+	for i > 0 || (j > 0 && !substring) {
+		switch {
+		case i > 0 && j > 0 && a[i-1] == b[j-1] && dist[i][j] == dist[i-1][j-1]:
+			ops = append(ops, op{'=', a[i-1]})
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && dist[i][j] == dist[i-1][j-1]+1:
+			ops = append(ops, op{'+', b[j-1]}, op{'-', a[i-1]})
+			i, j = i-1, j-1
+		case i > 0 && dist[i][j] == dist[i-1][j]+1:
+			ops = append(ops, op{'-', a[i-1]})
+			i--
+		default:
+			ops = append(ops, op{'+', b[j-1]})
+			j--
+		}
+	}
+
+	var diff strings.Builder
+	var current byte = '='
+	for k := len(ops) - 1; k >= 0; k-- {
+		if ops[k].kind != current {
+			closeDiffOp(&diff, current)
+			switch ops[k].kind {
+			case '-':
+				diff.WriteString("[-")
+			case '+':
+				diff.WriteString("{+")
+			}
+			current = ops[k].kind
+		}
+		diff.WriteRune(ops[k].r)
+	}
+	closeDiffOp(&diff, current)
+	return distance, diff.String()
+}
+
+func closeDiffOp(b *strings.Builder, kind byte) {
+	switch kind {
+	case '-':
+		b.WriteString("-]")
+	case '+':
+		b.WriteString("+}")
+	}
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
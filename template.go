@@ -0,0 +1,119 @@
+package gomocket
+
+// Clone returns not attached copy of the mock with fresh trigger state.
+// Slices, reply rows and exception hooks are copied, so changing the clone never affects the original.
+func (fr *FakeResponse) Clone() *FakeResponse {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	clone := &FakeResponse{
+		Pattern:                fr.Pattern,
+		MatchPriority:          fr.MatchPriority,
+		Strict:                 fr.Strict,
+		Args:                   cloneValues(fr.Args),
+		Response:               cloneRows(fr.Response),
+		Dataset:                cloneRows(fr.Dataset),
+		Columns:                append([]string(nil), fr.Columns...),
+		Once:                   fr.Once,
+		MaxTriggeredTimes:      fr.MaxTriggeredTimes,
+		OnlyOnCall:             fr.OnlyOnCall,
+		SkipCalls:              fr.SkipCalls,
+		ExpectedTriggeredTimes: fr.ExpectedTriggeredTimes,
+		ExpectedAtLeast:        fr.ExpectedAtLeast,
+		ExpectedAtMost:         fr.ExpectedAtMost,
+		ExpectNever:            fr.ExpectNever,
+		Callback:               fr.Callback,
+		RowsAffected:           fr.RowsAffected,
+		LastInsertID:           fr.LastInsertID,
+		Error:                  fr.Error,
+		RowsAffectedSequence:   append([]int64(nil), fr.RowsAffectedSequence...),
+		LastInsertIDSequence:   append([]int64(nil), fr.LastInsertIDSequence...),
+		Errors:                 append([]error(nil), fr.Errors...),
+		Scenario:               fr.Scenario,
+		RequiredScenarioState:  fr.RequiredScenarioState,
+		NewScenarioState:       fr.NewScenarioState,
+		ExhaustedPolicy:        fr.ExhaustedPolicy,
+		RowError:               fr.RowError,
+		RowErrorAfter:          fr.RowErrorAfter,
+		RowSource:              fr.RowSource,
+		ReplyFunc:              fr.ReplyFunc,
+		ExecFunc:               fr.ExecFunc,
+		IsTemplated:            fr.IsTemplated,
+		Exceptions:             &Exceptions{},
+	}
+	if fr.Replies != nil {
+		clone.Replies = make([][]map[string]interface{}, len(fr.Replies))
+		for i, reply := range fr.Replies {
+			clone.Replies[i] = cloneRows(reply)
+		}
+	}
+	if fr.Rows != nil {
+		clone.Rows = make([][]interface{}, len(fr.Rows))
+		for i, values := range fr.Rows {
+			clone.Rows[i] = cloneValues(values)
+		}
+	}
+	for _, set := range fr.ReplySets {
+		clone.ReplySets = append(clone.ReplySets, set.clone())
+	}
+	if fr.ColumnTypes != nil {
+		clone.ColumnTypes = make(map[string]ColumnType, len(fr.ColumnTypes))
+		for col, ct := range fr.ColumnTypes {
+			clone.ColumnTypes[col] = ct
+		}
+	}
+	clone.convertReplies()
+	if fr.Exceptions != nil {
+		*clone.Exceptions = *fr.Exceptions
+	}
+	return clone
+}
+
+func cloneValues(values []interface{}) []interface{} {
+	if values == nil {
+		return nil
+	}
+	return append(make([]interface{}, 0, len(values)), values...)
+}
+
+func cloneRows(rows []map[string]interface{}) []map[string]interface{} {
+	if rows == nil {
+		return nil
+	}
+	clone := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		clone[i] = make(map[string]interface{}, len(row))
+		for col, value := range row {
+			clone[i][col] = value
+		}
+	}
+	return clone
+}
+
+// Template is a prototype of nearly identical mocks. Derived mocks inherit pattern, priority,
+// hooks and reply of the template and override some of them by the usual chain of calls.
+//
+//	tpl := NewTemplate()
+//	tpl.WithQuery(`SELECT * FROM users WHERE id`).WithMatchPriority(TESTCASE)
+//	Catcher.NewMockFrom(tpl).WithArgs(1).WithReply(first)
+//	Catcher.NewMockFrom(tpl).WithArgs(2).WithReply(second)
+type Template struct {
+	*FakeResponse // Prototype configured by chain of calls, never attached to Catcher
+}
+
+// NewTemplate creates empty template
+func NewTemplate() *Template {
+	return &Template{FakeResponse: &FakeResponse{Exceptions: &Exceptions{}, Response: make([]map[string]interface{}, 0)}}
+}
+
+// Derive returns not attached mock based on template
+func (t *Template) Derive() *FakeResponse {
+	return t.FakeResponse.Clone()
+}
+
+// NewMockFrom creates new mock based on template and attaches it
+func (mc *MockCatcher) NewMockFrom(t *Template) *FakeResponse {
+	fr := t.Derive()
+	mc.Attach([]*FakeResponse{fr})
+	return fr
+}
@@ -16,7 +16,9 @@ func normalize(origin string) string {
 	return s
 }
 
-func completeStatement(prepareStatment string, args []driver.NamedValue) (query string) {
+// matchingStatement puts args instead of ? placeholders the way mocks of queries are matched,
+// strings are not quoted and other placeholders are kept as they are
+func matchingStatement(prepareStatment string, args []driver.NamedValue) string {
 	if !strings.Contains(prepareStatment, "?") || len(args) == 0 {
 		return prepareStatment
 	}
@@ -36,3 +38,44 @@ func completeStatement(prepareStatment string, args []driver.NamedValue) (query
 	}
 	return prepareStatment
 }
+
+// completeStatement puts args rendered as literals of the dialect instead of ?, $1, :name and @p1 placeholders
+func completeStatement(prepareStatment string, args []driver.NamedValue, dialect Dialect) (query string) {
+	if len(args) == 0 {
+		return prepareStatment
+	}
+	tokens := lexSQL(prepareStatment, dialect)
+	var b strings.Builder
+	for _, t := range tokens {
+		if t.kind != tokenPlaceholder {
+			b.WriteString(t.text)
+			continue
+		}
+		if arg, ok := placeholderArg(t, args); ok {
+			b.WriteString(dialect.Literal(arg.Value))
+		} else {
+			b.WriteString(t.text)
+		}
+	}
+	return b.String()
+}
+
+// placeholderArg finds arg bound to placeholder, :name ones are looked up by name first
+func placeholderArg(t sqlToken, args []driver.NamedValue) (driver.NamedValue, bool) {
+	if t.style == placeholderColon {
+		for _, arg := range args {
+			if arg.Name != "" && arg.Name == t.name {
+				return arg, true
+			}
+		}
+	}
+	for _, arg := range args {
+		if arg.Ordinal == t.ordinal {
+			return arg, true
+		}
+	}
+	if t.ordinal >= 1 && t.ordinal <= len(args) && args[t.ordinal-1].Ordinal == 0 {
+		return args[t.ordinal-1], true
+	}
+	return driver.NamedValue{}, false
+}
@@ -0,0 +1,209 @@
+package gomocket
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+)
+
+// Kinds of issues found by Validate
+const (
+	IssueShadowed    = "shadowed"    // Mock could never be reached because of another mock checked before it
+	IssueAmbiguous   = "ambiguous"   // Received query matched several mocks with the same priority
+	IssueUnreachable = "unreachable" // Mock was not triggered by any received query
+	IssueInvalid     = "invalid"     // Reply of mock holds value which could not be converted to driver.Value
+)
+
+// receivedCall is a journal record of one received query
+type receivedCall struct {
+	statement     string              // Normalized query with placeholders as it was prepared
+	query         string              // Normalized query as it was matched
+	queryWithArgs string              // Query with args put in place of placeholders
+	args          []driver.NamedValue // Bound args
+	mock          *FakeResponse       // Triggered mock, nil if nothing matched
+}
+
+// journal records received call, should be called under mc.mu lock
+func (mc *MockCatcher) journal(statement, query, queryWithArgs string, args []driver.NamedValue, fr *FakeResponse) {
+	mc.calls = append(mc.calls, receivedCall{
+		statement:     statement,
+		query:         query,
+		queryWithArgs: queryWithArgs,
+		args:          args,
+		mock:          fr,
+	})
+}
+
+// ValidationIssue describes one problem with registered mocks
+type ValidationIssue struct {
+	Kind    string        // One of IssueShadowed, IssueAmbiguous, IssueUnreachable or IssueInvalid
+	Mock    *FakeResponse // Mock with the problem
+	Other   *FakeResponse // Mock which shadows or is ambiguous with Mock, nil for unreachable ones
+	Message string        // Human readable description
+}
+
+// ValidationReport holds all issues found by Validate
+type ValidationReport struct {
+	Issues []ValidationIssue
+}
+
+// OK returns true when no issues were found
+func (r *ValidationReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// String formats report to be printed in logs or test failures
+func (r *ValidationReport) String() string {
+	if r.OK() {
+		return "mock_catcher: no issues found"
+	}
+	msgs := make([]string, 0, len(r.Issues)+1)
+	msgs = append(msgs, "mock_catcher: validation issues:")
+	for _, issue := range r.Issues {
+		msgs = append(msgs, fmt.Sprintf("  [%s] %s", issue.Kind, issue.Message))
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Validate looks for mocks which are shadowed by other ones, ambiguous matches of received queries
+// and mocks which were not triggered by any received query
+func (mc *MockCatcher) Validate() *ValidationReport {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.sortMocks()
+
+	report := &ValidationReport{}
+	for _, resp := range mc.Mocks {
+		resp.mu.RLock()
+		err := resp.replyErr
+		resp.mu.RUnlock()
+		if err != nil {
+			report.Issues = append(report.Issues, ValidationIssue{
+				Kind:    IssueInvalid,
+				Mock:    resp,
+				Message: strings.TrimPrefix(err.Error(), "mock_catcher: "),
+			})
+		}
+	}
+	for i, resp := range mc.Mocks {
+		if resp.marker() != "" {
+			continue // Markers trigger only mocks with exactly their pattern
+		}
+		for _, other := range mc.Mocks[:i] {
+			if shadows(other, resp) {
+				report.Issues = append(report.Issues, ValidationIssue{
+					Kind:    IssueShadowed,
+					Mock:    resp,
+					Other:   other,
+					Message: fmt.Sprintf("mock %s is shadowed by mock %s", describeMock(resp), describeMock(other)),
+				})
+				break
+			}
+		}
+	}
+
+	type pair struct{ winner, other *FakeResponse }
+	reported := make(map[pair]bool)
+	for _, call := range mc.calls {
+		if call.mock == nil || call.isMarker() {
+			continue
+		}
+		for _, other := range mc.Mocks {
+			if other == call.mock || other.MatchPriority != call.mock.MatchPriority || reported[pair{call.mock, other}] {
+				continue
+			}
+			if other.isQueryMatch(call.query) && other.isArgsMatch(call.args) {
+				reported[pair{call.mock, other}] = true
+				report.Issues = append(report.Issues, ValidationIssue{
+					Kind:  IssueAmbiguous,
+					Mock:  call.mock,
+					Other: other,
+					Message: fmt.Sprintf("query %s matches mock %s and mock %s with the same priority %d, picked by %s",
+						call.queryWithArgs, describeMock(call.mock), describeMock(other), other.MatchPriority, winReason([]*FakeResponse{call.mock, other})),
+				})
+			}
+		}
+	}
+
+	if len(mc.calls) == 0 {
+		return report
+	}
+	for _, resp := range mc.Mocks {
+		resp.mu.RLock()
+		triggered := resp.TriggeredTimes
+		never := resp.ExpectNever // Set by Never, AtMost(0) and Between(0, 0)
+		resp.mu.RUnlock()
+		if triggered > 0 || never {
+			continue // Mocks expected to be never triggered are unreachable on purpose
+		}
+		matched := 0
+		for _, call := range mc.calls {
+			if !call.isMarker() && resp.isQueryMatch(call.query) && resp.isArgsMatch(call.args) {
+				matched++
+			}
+		}
+		msg := fmt.Sprintf("mock %s never matched any of %d received queries", describeMock(resp), len(mc.calls))
+		if matched > 0 {
+			msg = fmt.Sprintf("mock %s matched %d received queries, but was never triggered", describeMock(resp), matched)
+		}
+		report.Issues = append(report.Issues, ValidationIssue{Kind: IssueUnreachable, Mock: resp, Message: msg})
+	}
+	return report
+}
+
+// warnAmbiguous logs other mocks with the same priority which match the query picked by mock with index picked,
+// the call is already counted by all matching mocks
+func (mc *MockCatcher) warnAmbiguous(query, queryWithArgs string, args []driver.NamedValue, picked int) {
+	winner := mc.Mocks[picked]
+	for _, other := range mc.Mocks[picked+1:] {
+		if other.MatchPriority != winner.MatchPriority {
+			break
+		}
+		if !other.isQueryMatch(query) || !other.isArgsMatch(args) || !mc.isScenarioMatch(other) {
+			continue
+		}
+		other.mu.RLock()
+		allowed := other.isCallAllowed(other.MatchedTimes)
+		other.mu.RUnlock()
+		if allowed {
+			log.Printf("mock_catcher: [AMBIGUOUS QUERY]: %s matches mock %s and mock %s with the same priority %d, picked by %s",
+				queryWithArgs, describeMock(winner), describeMock(other), winner.MatchPriority, winReason([]*FakeResponse{winner, other}))
+		}
+	}
+}
+
+// shadows returns true if mock a is checked before b and catches every query b could catch
+func shadows(a, b *FakeResponse) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if a.Once || a.MaxTriggeredTimes > 0 || a.OnlyOnCall > 0 || a.SkipCalls > 0 {
+		return false
+	}
+	if a.Scenario != "" && a.RequiredScenarioState != "" &&
+		(a.Scenario != b.Scenario || a.RequiredScenarioState != b.RequiredScenarioState) {
+		return false
+	}
+	if a.Args != nil && !reflect.DeepEqual(a.Args, b.Args) {
+		return false
+	}
+	switch {
+	case a.Pattern == "":
+		return true
+	case a.Strict:
+		return b.Strict && a.Pattern == b.Pattern
+	default:
+		return b.Pattern != "" && strings.Contains(b.Pattern, a.Pattern)
+	}
+}
+
+// describeMock formats mock the same way as matching logs do
+func describeMock(fr *FakeResponse) string {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	return fmt.Sprintf("{pattern: %s, args: %v, priority: %d}", fr.Pattern, fr.Args, fr.MatchPriority)
+}
@@ -0,0 +1,127 @@
+package gomocket
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// ReplyValueError is returned by queries triggering mock which reply holds value not convertible to driver.Value
+type ReplyValueError struct {
+	Mock   *FakeResponse // Mock with invalid reply
+	Reply  int           // Index of reply set by WithReplies or WithReplySets, -1 for the main reply
+	Row    int           // Index of the row
+	Column string        // Name of the column, empty for tabular rows longer than declared columns
+	Err    error         // Conversion error
+}
+
+func (e *ReplyValueError) Error() string {
+	reply := ""
+	if e.Reply >= 0 {
+		reply = fmt.Sprintf("reply %d, ", e.Reply)
+	}
+	return fmt.Sprintf("mock_catcher: mock %s has invalid value in %srow %d, column %q: %v",
+		describeMock(e.Mock), reply, e.Row, e.Column, e.Err)
+}
+
+func (e *ReplyValueError) Unwrap() error {
+	return e.Err
+}
+
+// convertValue converts reply value to one of driver.Value types: int64, float64, bool, []byte, string,
+// time.Time or nil. driver.Valuer values are converted by their Value method.
+func convertValue(v interface{}) (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+// convertRecords converts values of map reply into new records, so caller's maps are never changed
+func (fr *FakeResponse) convertRecords(records []map[string]interface{}, reply int) ([]map[string]interface{}, error) {
+	if records == nil {
+		return nil, nil
+	}
+	converted := make([]map[string]interface{}, len(records))
+	var firstErr error
+	for i, record := range records {
+		converted[i] = make(map[string]interface{}, len(record))
+		for col, value := range record {
+			v, err := convertValue(value)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = &ReplyValueError{Mock: fr, Reply: reply, Row: i, Column: col, Err: err}
+				}
+				v = value
+			}
+			converted[i][col] = v
+		}
+	}
+	return converted, firstErr
+}
+
+// convertRow converts values of tabular reply row in place
+func (fr *FakeResponse) convertRow(values []interface{}, columns []string, reply, row int) error {
+	var firstErr error
+	for i, value := range values {
+		v, err := convertValue(value)
+		if err != nil {
+			if firstErr == nil {
+				column := ""
+				if i < len(columns) {
+					column = columns[i]
+				}
+				firstErr = &ReplyValueError{Mock: fr, Reply: reply, Row: row, Column: column, Err: err}
+			}
+			continue
+		}
+		values[i] = v
+	}
+	return firstErr
+}
+
+// convertReplies converts all reply values of the mock and keeps the first error, should be called under fr.mu lock
+func (fr *FakeResponse) convertReplies() {
+	fr.replyErr = nil
+	keep := func(err error) {
+		if fr.replyErr == nil {
+			fr.replyErr = err
+		}
+	}
+	var err error
+	fr.Response, err = fr.convertRecords(fr.Response, -1)
+	keep(err)
+	fr.Dataset, err = fr.convertRecords(fr.Dataset, -1)
+	keep(err)
+	if fr.Replies != nil {
+		fr.Replies = append([][]map[string]interface{}(nil), fr.Replies...)
+	}
+	for i, reply := range fr.Replies {
+		fr.Replies[i], err = fr.convertRecords(reply, i)
+		keep(err)
+	}
+	for i, values := range fr.Rows {
+		fr.Rows[i] = append([]interface{}(nil), values...)
+		keep(fr.convertRow(fr.Rows[i], fr.Columns, -1, i))
+	}
+	if fr.ReplySets != nil {
+		fr.ReplySets = append([]*ReplySet(nil), fr.ReplySets...)
+	}
+	for i, set := range fr.ReplySets {
+		fr.ReplySets[i], err = fr.convertSet(set, i)
+		keep(err)
+	}
+}
+
+// convertSet converts values of result set into its copy and returns the first error
+func (fr *FakeResponse) convertSet(set *ReplySet, reply int) (*ReplySet, error) {
+	set = set.clone()
+	var firstErr error
+	var err error
+	set.Response, err = fr.convertRecords(set.Response, reply)
+	if err != nil {
+		firstErr = err
+	}
+	for j, values := range set.Rows {
+		if err := fr.convertRow(values, set.Columns, reply, j); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return set, firstErr
+}